@@ -9,9 +9,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/dnd-it/action-yaml-update/internal/github"
+	"github.com/dnd-it/action-yaml-update/internal/forge"
 	"github.com/dnd-it/action-yaml-update/internal/gitops"
 	"github.com/dnd-it/action-yaml-update/internal/inputs"
+	"github.com/dnd-it/action-yaml-update/internal/manifest"
 	"github.com/dnd-it/action-yaml-update/internal/outputs"
 	"github.com/dnd-it/action-yaml-update/internal/updater"
 )
@@ -31,81 +32,25 @@ func run() error {
 	if err != nil {
 		return err
 	}
+	if cfg.Token != "" {
+		outputs.Mask(cfg.Token)
+	}
 
 	outputs.LogInfo(fmt.Sprintf("Mode: %s", cfg.Mode))
-	outputs.LogInfo(fmt.Sprintf("Files: %s", strings.Join(cfg.Files, ", ")))
 	if cfg.DryRun {
 		outputs.LogInfo("Dry run mode enabled — no changes will be persisted")
 	}
 
-	// Process each file
-	var allChanges []updater.Change
-	var changedFiles []string
-	var allDiffs []string
-
-	for _, filePath := range cfg.Files {
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			return fmt.Errorf("file not found: %s", filePath)
-		}
-
-		outputs.LogGroup(fmt.Sprintf("Processing %s", filePath))
-
-		originalContent, err := os.ReadFile(filePath)
-		if err != nil {
-			return fmt.Errorf("read file %s: %w", filePath, err)
-		}
-
-		doc, err := updater.LoadYAML(originalContent)
-		if err != nil {
-			return fmt.Errorf("parse yaml %s: %w", filePath, err)
-		}
-
-		if doc == nil || doc.Root == nil {
-			outputs.LogWarning(fmt.Sprintf("Skipping empty YAML file: %s", filePath))
-			outputs.LogEndGroup()
-			continue
-		}
-
-		var changes []updater.Change
-		if cfg.Mode == "key" {
-			changes, err = updater.UpdateKeys(doc, cfg.Keys, cfg.Values)
-			if err != nil {
-				outputs.LogEndGroup()
-				return fmt.Errorf("update failed for %s: %w", filePath, err)
-			}
-		} else {
-			changes = updater.UpdateImageTags(doc, cfg.ImageName, cfg.ImageTag)
-		}
-
-		if len(changes) > 0 {
-			for _, c := range changes {
-				outputs.LogInfo(fmt.Sprintf("  %s: %v -> %v", c.Key, c.Old, c.New))
-			}
-			allChanges = append(allChanges, changes...)
-			changedFiles = append(changedFiles, filePath)
-
-			newContent, err := updater.DumpYAML(doc)
-			if err != nil {
-				outputs.LogEndGroup()
-				return fmt.Errorf("dump yaml %s: %w", filePath, err)
-			}
-
-			fileDiff := updater.Diff(filePath, originalContent, newContent)
-			if fileDiff != "" {
-				allDiffs = append(allDiffs, fileDiff)
-			}
+	if cfg.Mode == "manifest" {
+		return runManifest(ctx, cfg)
+	}
 
-			if !cfg.DryRun {
-				if err := os.WriteFile(filePath, newContent, 0644); err != nil {
-					outputs.LogEndGroup()
-					return fmt.Errorf("write file %s: %w", filePath, err)
-				}
-			}
-		} else {
-			outputs.LogInfo(fmt.Sprintf("  No changes needed for %s", filePath))
-		}
+	outputs.LogInfo(fmt.Sprintf("Files: %s", strings.Join(cfg.Files, ", ")))
 
-		outputs.LogEndGroup()
+	// Process each file
+	changedFiles, allChanges, allDiffs, err := applyUpdates(cfg)
+	if err != nil {
+		return err
 	}
 
 	// Write outputs
@@ -145,23 +90,102 @@ func run() error {
 		}
 	}
 
+	forgeKind := cfg.Forge
+	if forgeKind == "" {
+		forgeKind = forge.Detect()
+	}
+
+	forgeBaseURL := cfg.GithubAPIURL
+	if forgeKind != "github" && cfg.ForgeURL != "" {
+		forgeBaseURL = cfg.ForgeURL
+	}
+
+	f, err := forge.New(forge.Config{
+		Kind:       forgeKind,
+		BaseURL:    forgeBaseURL,
+		GraphQLURL: cfg.GithubGraphQLURL,
+		Token:      cfg.Token,
+	})
+	if err != nil {
+		return fmt.Errorf("forge: %w", err)
+	}
+
 	if err := gitops.Configure(cfg.GitUserName, cfg.GitUserEmail, cfg.Token, cfg.GithubRepo, cfg.GithubServerURL); err != nil {
 		outputs.LogEndGroup()
 		return fmt.Errorf("git configure: %w", err)
 	}
 
+	if cfg.SigningKey != "" {
+		gitops.Sign(&gitops.SigningOptions{
+			Key:        cfg.SigningKey,
+			Passphrase: cfg.SigningKeyPassphrase,
+			Format:     cfg.SigningFormat,
+			Signoff:    cfg.Signoff,
+		})
+	} else if cfg.Signoff {
+		gitops.Sign(&gitops.SigningOptions{Signoff: true})
+	}
+	gitops.SetAmendPrevious(cfg.AmendPrevious)
+
 	targetBranch := cfg.TargetBranch
 	if targetBranch == "" {
 		targetBranch = gitops.GetDefaultBranch()
 	}
 
+	prBody := cfg.PRBody
+	if prBody == "" {
+		var lines []string
+		for _, c := range allChanges {
+			lines = append(lines, fmt.Sprintf("- `%s`: `%v` → `%v`", c.Key, c.Old, c.New))
+		}
+		prBody = "## Changes\n\n" + strings.Join(lines, "\n")
+	}
+
+	// AGit mode commits directly on top of the target branch and pushes
+	// to refs/for/<target>, letting the server materialize the PR from
+	// that single push. It skips CreateBranch/CommitAndPush/CreatePullRequest entirely.
+	if cfg.UseAGit {
+		if err := gitops.CheckoutBase(targetBranch); err != nil {
+			outputs.LogEndGroup()
+			return fmt.Errorf("checkout %s: %w", targetBranch, err)
+		}
+
+		commitResult, err := gitops.Commit(changedFiles, cfg.CommitMessage, targetBranch)
+		if err != nil {
+			outputs.LogEndGroup()
+			return fmt.Errorf("commit: %w", err)
+		}
+		outputs.SetOutput("commit_sha", commitResult.SHA)
+		outputs.SetOutput("commit_signed", fmt.Sprintf("%t", commitResult.Signed))
+		outputs.SetOutput("signing_key_id", commitResult.SigningKeyID)
+
+		topic := cfg.PRBranch
+		if topic == "" {
+			topic = generateBranchName(cfg)
+		}
+
+		prURL, err := gitops.PushForReview(targetBranch, topic, cfg.PRTitle, prBody)
+		if err != nil {
+			outputs.LogEndGroup()
+			return fmt.Errorf("push for review: %w", err)
+		}
+		outputs.SetOutput("pr_number", "")
+		outputs.SetOutput("pr_url", prURL)
+		outputs.LogInfo(fmt.Sprintf("Pushed for review: %s", prURL))
+		outputs.LogEndGroup()
+
+		outputs.AppendSummary(buildSummary(allChanges, allDiffs, prURL))
+		outputs.LogInfo("Done!")
+		return nil
+	}
+
 	var commitBranch string
 	if cfg.CreatePR {
 		prBranch := cfg.PRBranch
 		if prBranch == "" {
 			prBranch = generateBranchName(cfg)
 		}
-		if err := gitops.CreateBranch(prBranch, targetBranch); err != nil {
+		if err := gitops.CreateBranch(prBranch, targetBranch, cfg.ForceReuseBranch); err != nil {
 			outputs.LogEndGroup()
 			return fmt.Errorf("create branch: %w", err)
 		}
@@ -170,40 +194,44 @@ func run() error {
 		commitBranch = targetBranch
 	}
 
-	sha, err := gitops.CommitAndPush(changedFiles, cfg.CommitMessage, commitBranch)
+	regenerate := func() ([]string, error) {
+		files, _, _, err := applyUpdates(cfg)
+		return files, err
+	}
+
+	commitResult, retries, err := gitops.CommitAndPushWithRetry(changedFiles, cfg.CommitMessage, commitBranch, regenerate)
 	if err != nil {
 		outputs.LogEndGroup()
 		return fmt.Errorf("commit and push: %w", err)
 	}
-	outputs.SetOutput("commit_sha", sha)
-	outputs.LogInfo(fmt.Sprintf("Committed and pushed: %s", sha))
+	outputs.SetOutput("commit_sha", commitResult.SHA)
+	outputs.SetOutput("commit_signed", fmt.Sprintf("%t", commitResult.Signed))
+	outputs.SetOutput("signing_key_id", commitResult.SigningKeyID)
+	outputs.SetOutput("push_conflict_retries", fmt.Sprintf("%d", retries))
+	if retries > 0 {
+		outputs.LogInfo(fmt.Sprintf("Resynced onto %s and retried %d time(s) after a concurrent push", commitBranch, retries))
+	}
+	outputs.LogInfo(fmt.Sprintf("Committed and pushed: %s", commitResult.SHA))
 	outputs.LogEndGroup()
 
 	// Create PR
+	var prURL string
 	if cfg.CreatePR {
 		outputs.LogGroup("Pull request")
 
-		prBody := cfg.PRBody
-		if prBody == "" {
-			var lines []string
-			for _, c := range allChanges {
-				lines = append(lines, fmt.Sprintf("- `%s`: `%v` → `%v`", c.Key, c.Old, c.New))
-			}
-			prBody = "## Changes\n\n" + strings.Join(lines, "\n")
-		}
-
-		prData, err := github.CreatePullRequest(ctx, cfg.GithubAPIURL, cfg.Token, owner, repo, cfg.PRTitle, prBody, commitBranch, targetBranch)
+		prData, err := f.CreatePullRequest(ctx, owner, repo, cfg.PRTitle, prBody, commitBranch, targetBranch)
 		if err != nil {
 			outputs.LogEndGroup()
 			return fmt.Errorf("create pull request: %w", err)
 		}
+		prURL = prData.HTMLURL
 
 		outputs.SetOutput("pr_number", fmt.Sprintf("%d", prData.Number))
 		outputs.SetOutput("pr_url", prData.HTMLURL)
 		outputs.LogInfo(fmt.Sprintf("Created PR #%d: %s", prData.Number, prData.HTMLURL))
 
 		if len(cfg.PRLabels) > 0 {
-			if err := github.AddLabels(ctx, cfg.GithubAPIURL, cfg.Token, owner, repo, prData.Number, cfg.PRLabels); err != nil {
+			if err := f.AddLabels(ctx, owner, repo, prData.Number, cfg.PRLabels); err != nil {
 				outputs.LogWarning(fmt.Sprintf("Failed to add labels: %v", err))
 			} else {
 				outputs.LogInfo(fmt.Sprintf("Added labels: %s", strings.Join(cfg.PRLabels, ", ")))
@@ -211,15 +239,15 @@ func run() error {
 		}
 
 		if len(cfg.PRReviewers) > 0 {
-			if err := github.RequestReviewers(ctx, cfg.GithubAPIURL, cfg.Token, owner, repo, prData.Number, cfg.PRReviewers); err != nil {
+			if err := f.RequestReviewers(ctx, owner, repo, prData.Number, cfg.PRReviewers); err != nil {
 				outputs.LogWarning(fmt.Sprintf("Failed to request reviewers: %v", err))
 			} else {
 				outputs.LogInfo(fmt.Sprintf("Requested reviewers: %s", strings.Join(cfg.PRReviewers, ", ")))
 			}
 		}
 
-		if cfg.AutoMerge && prData.NodeID != "" {
-			if err := github.EnableAutoMerge(ctx, cfg.GithubGraphQLURL, cfg.Token, prData.NodeID, cfg.MergeMethod); err != nil {
+		if cfg.AutoMerge {
+			if err := f.EnableAutoMerge(ctx, owner, repo, prData, cfg.MergeMethod); err != nil {
 				outputs.LogWarning(fmt.Sprintf("Failed to enable auto-merge: %v", err))
 			} else {
 				outputs.LogInfo(fmt.Sprintf("Enabled auto-merge (%s)", cfg.MergeMethod))
@@ -232,12 +260,420 @@ func run() error {
 		outputs.SetOutput("pr_url", "")
 	}
 
+	outputs.AppendSummary(buildSummary(allChanges, allDiffs, prURL))
 	outputs.LogInfo("Done!")
 	return nil
 }
 
+// buildSummary renders a Job Summary for a run: a table of every change
+// made (file, key, old -> new), the unified diff for each touched file
+// in a collapsible block, and a link to the resulting PR, if any.
+func buildSummary(changes []updater.Change, diffs []string, prURL string) string {
+	var b strings.Builder
+
+	b.WriteString("## YAML Update\n\n")
+
+	if len(changes) > 0 {
+		b.WriteString("| File | Key | Old | New |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, c := range changes {
+			fmt.Fprintf(&b, "| `%s` | `%s` | `%v` | `%v` |\n", c.File, c.Key, c.Old, c.New)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(diffs) > 0 {
+		b.WriteString("<details><summary>Diff</summary>\n\n")
+		b.WriteString("```diff\n")
+		b.WriteString(strings.Join(diffs, "\n"))
+		b.WriteString("\n```\n\n")
+		b.WriteString("</details>\n\n")
+	}
+
+	if prURL != "" {
+		fmt.Fprintf(&b, "Pull request: %s\n", prURL)
+	}
+
+	return b.String()
+}
+
+// applyUpdates runs mode=key/image's update logic across cfg.Files,
+// returning the files it changed, the individual key/value changes made,
+// and a unified diff per changed file. It's also used, unmodified, as the
+// regenerate step after CommitAndPushWithRetry resyncs onto a moved
+// target branch, so it must be safe to call more than once against the
+// same on-disk files.
+//
+// Each file is transparently layered with a sibling `<file><overlay-suffix>`
+// override, if one exists, before the update runs (see
+// updater.LoadYAMLWithOverlays); when cfg.WriteToOverlay is set, the
+// resulting change is written into that override file instead of the
+// base file, creating it if necessary.
+func applyUpdates(cfg *inputs.Config) (changedFiles []string, allChanges []updater.Change, allDiffs []string, err error) {
+	for _, filePath := range cfg.Files {
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			return nil, nil, nil, fmt.Errorf("file not found: %s", filePath)
+		}
+
+		outputs.LogGroup(fmt.Sprintf("Processing %s", filePath))
+
+		ov, err := updater.LoadYAMLWithOverlays(filePath, cfg.OverlaySuffix, "")
+		if err != nil {
+			outputs.LogEndGroup()
+			return nil, nil, nil, err
+		}
+
+		if len(ov.Base) == 0 {
+			outputs.LogWarning(fmt.Sprintf("Skipping empty YAML file: %s", filePath))
+			outputs.LogEndGroup()
+			continue
+		}
+
+		var changes []updater.Change
+		switch {
+		case cfg.Mode == "key":
+			changes, err = ov.ApplyUpdates(cfg.WriteToOverlay, func(doc *updater.Document) ([]updater.Change, error) {
+				return updater.UpdateKeys(doc, cfg.Keys, cfg.Values)
+			})
+		case len(cfg.Images) > 0:
+			changes, err = ov.ApplyUpdates(cfg.WriteToOverlay, func(doc *updater.Document) ([]updater.Change, error) {
+				return updater.UpdateImages(doc, cfg.Images)
+			})
+		default:
+			changes, err = ov.ApplyUpdates(cfg.WriteToOverlay, func(doc *updater.Document) ([]updater.Change, error) {
+				return updater.UpdateImageTags(doc, cfg.ImageName, cfg.ImageTag), nil
+			})
+		}
+		if err != nil {
+			outputs.LogEndGroup()
+			return nil, nil, nil, fmt.Errorf("update failed for %s: %w", filePath, err)
+		}
+
+		if len(changes) > 0 {
+			for i := range changes {
+				changes[i].File = filePath
+				outputs.LogInfo(fmt.Sprintf("  [doc %d] %s: %v -> %v", changes[i].DocIndex, changes[i].Key, changes[i].Old, changes[i].New))
+			}
+			allChanges = append(allChanges, changes...)
+
+			// The base file always gets dumped back out: ApplyUpdates
+			// mutates ov.Base in place for every document except index
+			// 0 when WriteToOverlay redirected it into the overlay, in
+			// which case this is a no-op rewrite of unchanged content.
+			files, diffs, err := dumpAndWrite(filePath, ov.Base, cfg.DryRun)
+			if err != nil {
+				outputs.LogEndGroup()
+				return nil, nil, nil, err
+			}
+			changedFiles = append(changedFiles, files...)
+			allDiffs = append(allDiffs, diffs...)
+
+			if cfg.WriteToOverlay && ov.Overlay != nil {
+				files, diffs, err := dumpAndWrite(ov.OverlayPath, []*updater.Document{ov.Overlay}, cfg.DryRun)
+				if err != nil {
+					outputs.LogEndGroup()
+					return nil, nil, nil, err
+				}
+				changedFiles = append(changedFiles, files...)
+				allDiffs = append(allDiffs, diffs...)
+			}
+		} else {
+			outputs.LogInfo(fmt.Sprintf("  No changes needed for %s", filePath))
+		}
+
+		outputs.LogEndGroup()
+	}
+
+	return changedFiles, allChanges, allDiffs, nil
+}
+
+// dumpAndWrite dumps docs, diffs the result against path's current
+// on-disk content (treating a missing file as empty), and — unless
+// dryRun — writes it back. It reports path as changed only when the
+// diff is non-empty, so a dump that happens to be byte-identical to
+// what's already there (e.g. the base file when every change in this
+// document stream was redirected into an overlay) isn't reported as a
+// touched file.
+func dumpAndWrite(path string, docs []*updater.Document, dryRun bool) (changedFiles, diffs []string, err error) {
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("read file %s: %w", path, err)
+	}
+
+	newContent, err := updater.DumpYAML(docs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dump yaml %s: %w", path, err)
+	}
+
+	diff := updater.Diff(path, original, newContent)
+	if diff == "" {
+		return nil, nil, nil
+	}
+
+	if !dryRun {
+		if err := os.WriteFile(path, newContent, 0644); err != nil {
+			return nil, nil, fmt.Errorf("write file %s: %w", path, err)
+		}
+	}
+
+	return []string{path}, []string{diff}, nil
+}
+
 func generateBranchName(cfg *inputs.Config) string {
-	seed := fmt.Sprintf("%v%v%v%s%s", cfg.Files, cfg.Keys, cfg.Values, cfg.ImageName, cfg.ImageTag)
+	seed := fmt.Sprintf("%v%v%v%s%s%v", cfg.Files, cfg.Keys, cfg.Values, cfg.ImageName, cfg.ImageTag, cfg.Images)
 	hash := sha256.Sum256([]byte(seed))
 	return fmt.Sprintf("yaml-update/%x-%d", hash[:4], time.Now().Unix())
 }
+
+// runManifest implements mode=manifest: instead of a single keys/values
+// or image-name/image-tag update, it loads a declarative manifest
+// describing many updates and emits one pull request per group.
+func runManifest(ctx context.Context, cfg *inputs.Config) error {
+	outputs.LogInfo(fmt.Sprintf("Manifest: %s", cfg.ManifestPath))
+
+	m, err := manifest.Load(cfg.ManifestPath)
+	if err != nil {
+		return err
+	}
+
+	if err := gitops.Configure(cfg.GitUserName, cfg.GitUserEmail, cfg.Token, cfg.GithubRepo, cfg.GithubServerURL); err != nil {
+		return fmt.Errorf("git configure: %w", err)
+	}
+
+	if cfg.SigningKey != "" {
+		gitops.Sign(&gitops.SigningOptions{
+			Key:        cfg.SigningKey,
+			Passphrase: cfg.SigningKeyPassphrase,
+			Format:     cfg.SigningFormat,
+			Signoff:    cfg.Signoff,
+		})
+	} else if cfg.Signoff {
+		gitops.Sign(&gitops.SigningOptions{Signoff: true})
+	}
+	gitops.SetAmendPrevious(cfg.AmendPrevious)
+
+	forgeKind := cfg.Forge
+	if forgeKind == "" {
+		forgeKind = forge.Detect()
+	}
+	forgeBaseURL := cfg.GithubAPIURL
+	if forgeKind != "github" && cfg.ForgeURL != "" {
+		forgeBaseURL = cfg.ForgeURL
+	}
+
+	f, err := forge.New(forge.Config{
+		Kind:       forgeKind,
+		BaseURL:    forgeBaseURL,
+		GraphQLURL: cfg.GithubGraphQLURL,
+		Token:      cfg.Token,
+	})
+	if err != nil {
+		return fmt.Errorf("forge: %w", err)
+	}
+
+	var owner, repo string
+	if cfg.GithubRepo != "" {
+		parts := strings.SplitN(cfg.GithubRepo, "/", 2)
+		if len(parts) == 2 {
+			owner, repo = parts[0], parts[1]
+		}
+	}
+
+	targetBranch := cfg.TargetBranch
+	if targetBranch == "" {
+		targetBranch = gitops.GetDefaultBranch()
+	}
+
+	var prURLs []string
+	for _, group := range m.Groups() {
+		outputs.LogGroup(fmt.Sprintf("Group %s", group.Name))
+
+		changedFiles, changes, err := applyManifestGroup(group, cfg.DryRun)
+		if err != nil {
+			outputs.LogEndGroup()
+			return fmt.Errorf("group %s: %w", group.Name, err)
+		}
+
+		if len(changedFiles) == 0 {
+			outputs.LogInfo("No changes needed")
+			outputs.LogEndGroup()
+			continue
+		}
+
+		if cfg.DryRun {
+			outputs.LogInfo(fmt.Sprintf("Dry run: %d file(s) would change", len(changedFiles)))
+			outputs.LogEndGroup()
+			continue
+		}
+
+		prBranch := manifestBranchName(group)
+		if err := gitops.CreateBranch(prBranch, targetBranch, cfg.ForceReuseBranch); err != nil {
+			outputs.LogEndGroup()
+			return fmt.Errorf("group %s: create branch: %w", group.Name, err)
+		}
+
+		title, body, labels, reviewers := manifestPRMeta(group, changes)
+
+		regenerate := func() ([]string, error) {
+			files, _, err := applyManifestGroup(group, false)
+			return files, err
+		}
+
+		if _, _, err := gitops.CommitAndPushWithRetry(changedFiles, title, prBranch, regenerate); err != nil {
+			outputs.LogEndGroup()
+			return fmt.Errorf("group %s: commit and push: %w", group.Name, err)
+		}
+
+		prData, err := f.CreatePullRequest(ctx, owner, repo, title, body, prBranch, targetBranch)
+		if err != nil {
+			outputs.LogEndGroup()
+			return fmt.Errorf("group %s: create pull request: %w", group.Name, err)
+		}
+		prURLs = append(prURLs, prData.HTMLURL)
+		outputs.LogInfo(fmt.Sprintf("Created PR #%d: %s", prData.Number, prData.HTMLURL))
+
+		if len(labels) > 0 {
+			if err := f.AddLabels(ctx, owner, repo, prData.Number, labels); err != nil {
+				outputs.LogWarning(fmt.Sprintf("Failed to add labels: %v", err))
+			}
+		}
+		if len(reviewers) > 0 {
+			if err := f.RequestReviewers(ctx, owner, repo, prData.Number, reviewers); err != nil {
+				outputs.LogWarning(fmt.Sprintf("Failed to request reviewers: %v", err))
+			}
+		}
+
+		outputs.LogEndGroup()
+	}
+
+	outputs.SetOutput("pr_url", strings.Join(prURLs, "\n"))
+	outputs.LogInfo("Done!")
+	return nil
+}
+
+// applyManifestGroup expands and applies every entry in group, returning
+// the files it changed and the changes made. Files are only rewritten on
+// disk when dryRun is false.
+func applyManifestGroup(group manifest.Group, dryRun bool) ([]string, []updater.Change, error) {
+	var changedFiles []string
+	var allChanges []updater.Change
+
+	for _, entry := range group.Entries {
+		files, err := entry.ExpandFiles()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, filePath := range files {
+			original, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("read file %s: %w", filePath, err)
+			}
+
+			docs, err := updater.LoadYAML(original)
+			if err != nil {
+				return nil, nil, fmt.Errorf("parse yaml %s: %w", filePath, err)
+			}
+			if len(docs) == 0 {
+				continue
+			}
+
+			var changes []updater.Change
+			for docIdx, doc := range docs {
+				var c []updater.Change
+				if entry.Type == "key" {
+					c, err = updater.UpdateKeys(doc, entry.Keys, entry.Values)
+					if err != nil {
+						return nil, nil, fmt.Errorf("update %s: %w", filePath, err)
+					}
+				} else {
+					c = updater.UpdateImageTags(doc, entry.ImageName, entry.ImageTag)
+				}
+				for i := range c {
+					c[i].DocIndex = docIdx
+				}
+				changes = append(changes, c...)
+			}
+
+			if len(changes) == 0 {
+				continue
+			}
+
+			for i := range changes {
+				changes[i].File = filePath
+			}
+			allChanges = append(allChanges, changes...)
+			changedFiles = append(changedFiles, filePath)
+
+			if !dryRun {
+				newContent, err := updater.DumpYAML(docs)
+				if err != nil {
+					return nil, nil, fmt.Errorf("dump yaml %s: %w", filePath, err)
+				}
+				if err := os.WriteFile(filePath, newContent, 0644); err != nil {
+					return nil, nil, fmt.Errorf("write file %s: %w", filePath, err)
+				}
+			}
+		}
+	}
+
+	return changedFiles, allChanges, nil
+}
+
+// manifestPRMeta derives PR title/body/labels/reviewers for a group from
+// its entries, falling back to a generated title and a changes table
+// when an entry doesn't specify one.
+func manifestPRMeta(group manifest.Group, changes []updater.Change) (title, body string, labels, reviewers []string) {
+	seenLabels := make(map[string]bool)
+	seenReviewers := make(map[string]bool)
+
+	for _, e := range group.Entries {
+		if title == "" {
+			title = e.PRTitle
+		}
+		if body == "" {
+			body = e.PRBody
+		}
+		for _, l := range e.Labels {
+			if !seenLabels[l] {
+				seenLabels[l] = true
+				labels = append(labels, l)
+			}
+		}
+		for _, r := range e.Reviewers {
+			if !seenReviewers[r] {
+				seenReviewers[r] = true
+				reviewers = append(reviewers, r)
+			}
+		}
+	}
+
+	if title == "" {
+		title = fmt.Sprintf("chore: update %s", group.Name)
+	}
+	if body == "" {
+		var lines []string
+		for _, c := range changes {
+			lines = append(lines, fmt.Sprintf("- `%s`: `%v` → `%v`", c.Key, c.Old, c.New))
+		}
+		body = "## Changes\n\n" + strings.Join(lines, "\n")
+	}
+
+	return title, body, labels, reviewers
+}
+
+// manifestBranchName derives a stable-ish PR branch name for a group,
+// honoring the first branch-prefix set by any of its entries.
+func manifestBranchName(group manifest.Group) string {
+	prefix := "yaml-update"
+	for _, e := range group.Entries {
+		if e.BranchPrefix != "" {
+			prefix = e.BranchPrefix
+			break
+		}
+	}
+
+	seed := fmt.Sprintf("%s%v", group.Name, group.Entries)
+	hash := sha256.Sum256([]byte(seed))
+	return fmt.Sprintf("%s/%x-%d", prefix, hash[:4], time.Now().Unix())
+}
@@ -0,0 +1,62 @@
+// Package forge abstracts pull/merge-request operations across Git
+// hosting platforms (GitHub, Gitea, GitLab) behind a single interface, so
+// the rest of the action doesn't need to know which one it's talking to.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// PRData describes a created pull or merge request.
+type PRData struct {
+	Number  int
+	HTMLURL string
+	NodeID  string
+}
+
+// Forge creates and manages pull requests on a specific Git hosting
+// platform.
+type Forge interface {
+	CreatePullRequest(ctx context.Context, owner, repo, title, body, head, base string) (*PRData, error)
+	AddLabels(ctx context.Context, owner, repo string, prNumber int, labels []string) error
+	RequestReviewers(ctx context.Context, owner, repo string, prNumber int, reviewers []string) error
+	EnableAutoMerge(ctx context.Context, owner, repo string, pr *PRData, mergeMethod string) error
+}
+
+// Config carries the connection details needed to construct any Forge
+// implementation.
+type Config struct {
+	Kind       string // "github", "gitea", or "gitlab"
+	BaseURL    string
+	GraphQLURL string // GitHub only
+	Token      string
+}
+
+// New constructs the Forge implementation selected by cfg.Kind. An empty
+// Kind defaults to GitHub.
+func New(cfg Config) (Forge, error) {
+	switch cfg.Kind {
+	case "", "github":
+		return newGitHub(cfg), nil
+	case "gitea":
+		return newGitea(cfg), nil
+	case "gitlab":
+		return newGitLab(cfg), nil
+	default:
+		return nil, fmt.Errorf("forge: unknown forge %q", cfg.Kind)
+	}
+}
+
+// Detect infers the forge kind from well-known CI environment variables
+// when the user hasn't set the `forge` input explicitly.
+func Detect() string {
+	if os.Getenv("CI_SERVER_URL") != "" {
+		return "gitlab"
+	}
+	if os.Getenv("GITEA_SERVER_URL") != "" {
+		return "gitea"
+	}
+	return "github"
+}
@@ -0,0 +1,60 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGiteaCreatePullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"number":   7,
+			"html_url": "/owner/repo/pulls/7",
+		})
+	}))
+	defer server.Close()
+
+	f := newGitea(Config{BaseURL: server.URL, Token: "tok"})
+
+	pr, err := f.CreatePullRequest(context.Background(), "owner", "repo", "title", "body", "feature", "main")
+	if err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	if pr.Number != 7 {
+		t.Errorf("Number = %d, want 7", pr.Number)
+	}
+}
+
+func TestGitLabCreatePullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"iid":     3,
+			"web_url": "https://gitlab.example.com/owner/repo/-/merge_requests/3",
+		})
+	}))
+	defer server.Close()
+
+	f := newGitLab(Config{BaseURL: server.URL, Token: "tok"})
+
+	mr, err := f.CreatePullRequest(context.Background(), "owner", "repo", "title", "body", "feature", "main")
+	if err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	if mr.Number != 3 {
+		t.Errorf("Number = %d, want 3", mr.Number)
+	}
+}
+
+func TestNewUnknownForge(t *testing.T) {
+	if _, err := New(Config{Kind: "bitbucket"}); err == nil {
+		t.Error("expected error for unknown forge kind")
+	}
+}
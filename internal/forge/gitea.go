@@ -0,0 +1,119 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// giteaForge implements Forge against a Gitea instance.
+type giteaForge struct {
+	baseURL string
+	token   string
+}
+
+func newGitea(cfg Config) Forge {
+	return &giteaForge{baseURL: cfg.BaseURL, token: cfg.Token}
+}
+
+func (f *giteaForge) client() (*gitea.Client, error) {
+	// SetGiteaVersion skips NewClient's server-version probe, which
+	// otherwise issues a GET the caller's server (or, in tests, fake)
+	// may not be set up to answer.
+	return gitea.NewClient(f.baseURL, gitea.SetToken(f.token), gitea.SetGiteaVersion(""))
+}
+
+func (f *giteaForge) CreatePullRequest(ctx context.Context, owner, repo, title, body, head, base string) (*PRData, error) {
+	client, err := f.client()
+	if err != nil {
+		return nil, fmt.Errorf("gitea client: %w", err)
+	}
+
+	pr, _, err := client.CreatePullRequest(owner, repo, gitea.CreatePullRequestOption{
+		Head:  head,
+		Base:  base,
+		Title: title,
+		Body:  body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create pull request: %w", err)
+	}
+
+	return &PRData{Number: int(pr.Index), HTMLURL: pr.HTMLURL}, nil
+}
+
+func (f *giteaForge) AddLabels(ctx context.Context, owner, repo string, prNumber int, labels []string) error {
+	client, err := f.client()
+	if err != nil {
+		return fmt.Errorf("gitea client: %w", err)
+	}
+
+	ids, err := resolveGiteaLabelIDs(client, owner, repo, labels)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := client.AddIssueLabels(owner, repo, int64(prNumber), gitea.IssueLabelsOption{Labels: ids}); err != nil {
+		return fmt.Errorf("add labels: %w", err)
+	}
+	return nil
+}
+
+func resolveGiteaLabelIDs(client *gitea.Client, owner, repo string, names []string) ([]int64, error) {
+	existing, _, err := client.ListRepoLabels(owner, repo, gitea.ListLabelsOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+
+	byName := make(map[string]int64, len(existing))
+	for _, l := range existing {
+		byName[l.Name] = l.ID
+	}
+
+	var ids []int64
+	var missing []string
+	for _, name := range names {
+		if id, ok := byName[name]; ok {
+			ids = append(ids, id)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("label(s) not found on repo: %s", strings.Join(missing, ", "))
+	}
+	return ids, nil
+}
+
+func (f *giteaForge) RequestReviewers(ctx context.Context, owner, repo string, prNumber int, reviewers []string) error {
+	client, err := f.client()
+	if err != nil {
+		return fmt.Errorf("gitea client: %w", err)
+	}
+
+	if _, err := client.CreateReviewRequests(owner, repo, int64(prNumber), gitea.PullReviewRequestOptions{Reviewers: reviewers}); err != nil {
+		return fmt.Errorf("request reviewers: %w", err)
+	}
+	return nil
+}
+
+func (f *giteaForge) EnableAutoMerge(ctx context.Context, owner, repo string, pr *PRData, mergeMethod string) error {
+	client, err := f.client()
+	if err != nil {
+		return fmt.Errorf("gitea client: %w", err)
+	}
+
+	ok, _, err := client.MergePullRequest(owner, repo, int64(pr.Number), gitea.MergePullRequestOption{
+		Style:                  gitea.MergeStyle(mergeMethod),
+		MergeWhenChecksSucceed: true,
+	})
+	if err != nil {
+		return fmt.Errorf("enable auto-merge: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("enable auto-merge: request was not accepted")
+	}
+	return nil
+}
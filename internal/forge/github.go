@@ -0,0 +1,38 @@
+package forge
+
+import (
+	"context"
+
+	"github.com/dnd-it/action-yaml-update/internal/github"
+)
+
+// githubForge adapts the internal/github client to the Forge interface.
+type githubForge struct {
+	apiURL     string
+	graphqlURL string
+	token      string
+}
+
+func newGitHub(cfg Config) Forge {
+	return &githubForge{apiURL: cfg.BaseURL, graphqlURL: cfg.GraphQLURL, token: cfg.Token}
+}
+
+func (f *githubForge) CreatePullRequest(ctx context.Context, owner, repo, title, body, head, base string) (*PRData, error) {
+	pr, err := github.CreatePullRequest(ctx, f.apiURL, f.token, owner, repo, title, body, head, base)
+	if err != nil {
+		return nil, err
+	}
+	return &PRData{Number: pr.Number, HTMLURL: pr.HTMLURL, NodeID: pr.NodeID}, nil
+}
+
+func (f *githubForge) AddLabels(ctx context.Context, owner, repo string, prNumber int, labels []string) error {
+	return github.AddLabels(ctx, f.apiURL, f.token, owner, repo, prNumber, labels)
+}
+
+func (f *githubForge) RequestReviewers(ctx context.Context, owner, repo string, prNumber int, reviewers []string) error {
+	return github.RequestReviewers(ctx, f.apiURL, f.token, owner, repo, prNumber, reviewers)
+}
+
+func (f *githubForge) EnableAutoMerge(ctx context.Context, owner, repo string, pr *PRData, mergeMethod string) error {
+	return github.EnableAutoMerge(ctx, f.graphqlURL, f.token, pr.NodeID, mergeMethod)
+}
@@ -0,0 +1,108 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+// gitlabForge implements Forge against a GitLab instance, mapping pull
+// requests onto merge requests.
+type gitlabForge struct {
+	baseURL string
+	token   string
+}
+
+func newGitLab(cfg Config) Forge {
+	return &gitlabForge{baseURL: cfg.BaseURL, token: cfg.Token}
+}
+
+func (f *gitlabForge) client() (*gitlab.Client, error) {
+	return gitlab.NewClient(f.token, gitlab.WithBaseURL(f.baseURL))
+}
+
+func (f *gitlabForge) CreatePullRequest(ctx context.Context, owner, repo, title, body, head, base string) (*PRData, error) {
+	client, err := f.client()
+	if err != nil {
+		return nil, fmt.Errorf("gitlab client: %w", err)
+	}
+
+	mr, _, err := client.MergeRequests.CreateMergeRequest(owner+"/"+repo, &gitlab.CreateMergeRequestOptions{
+		Title:        gitlab.Ptr(title),
+		Description:  gitlab.Ptr(body),
+		SourceBranch: gitlab.Ptr(head),
+		TargetBranch: gitlab.Ptr(base),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("create merge request: %w", err)
+	}
+
+	return &PRData{Number: mr.IID, HTMLURL: mr.WebURL}, nil
+}
+
+func (f *gitlabForge) AddLabels(ctx context.Context, owner, repo string, prNumber int, labels []string) error {
+	client, err := f.client()
+	if err != nil {
+		return fmt.Errorf("gitlab client: %w", err)
+	}
+
+	labelOpts := gitlab.LabelOptions(labels)
+	_, _, err = client.MergeRequests.UpdateMergeRequest(owner+"/"+repo, prNumber, &gitlab.UpdateMergeRequestOptions{
+		AddLabels: &labelOpts,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("add labels: %w", err)
+	}
+	return nil
+}
+
+func (f *gitlabForge) RequestReviewers(ctx context.Context, owner, repo string, prNumber int, reviewers []string) error {
+	client, err := f.client()
+	if err != nil {
+		return fmt.Errorf("gitlab client: %w", err)
+	}
+
+	ids, err := resolveGitLabUserIDs(client, reviewers)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.MergeRequests.UpdateMergeRequest(owner+"/"+repo, prNumber, &gitlab.UpdateMergeRequestOptions{
+		ReviewerIDs: &ids,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("request reviewers: %w", err)
+	}
+	return nil
+}
+
+func resolveGitLabUserIDs(client *gitlab.Client, usernames []string) ([]int, error) {
+	var ids []int
+	for _, username := range usernames {
+		users, _, err := client.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(username)})
+		if err != nil {
+			return nil, fmt.Errorf("lookup user %s: %w", username, err)
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("user %s not found", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+func (f *gitlabForge) EnableAutoMerge(ctx context.Context, owner, repo string, pr *PRData, mergeMethod string) error {
+	client, err := f.client()
+	if err != nil {
+		return fmt.Errorf("gitlab client: %w", err)
+	}
+
+	_, _, err = client.MergeRequests.AcceptMergeRequest(owner+"/"+repo, pr.Number, &gitlab.AcceptMergeRequestOptions{
+		MergeWhenPipelineSucceeds: gitlab.Ptr(true),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("enable auto-merge: %w", err)
+	}
+	return nil
+}
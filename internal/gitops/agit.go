@@ -0,0 +1,90 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// CheckoutBase fetches base and checks it out as a local branch of the
+// same name at origin's tip, rather than a dedicated PR branch.
+// AGit-style review pushes commit on top of the target branch itself.
+func CheckoutBase(base string) error {
+	if repo == nil {
+		return fmt.Errorf("gitops: repository not configured")
+	}
+
+	if err := fetchBranch(base); err != nil {
+		return err
+	}
+
+	baseRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", base), true)
+	if err != nil {
+		return fmt.Errorf("resolve origin/%s: %w", base, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("worktree: %w", err)
+	}
+
+	err = wt.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(base),
+		Hash:   baseRef.Hash(),
+		Create: true,
+	})
+	if err != nil {
+		return fmt.Errorf("checkout %s: %w", base, err)
+	}
+
+	return nil
+}
+
+// PushForReview pushes the current HEAD to refs/for/<target>, the AGit
+// convention used by Gitea/Gerrit-style servers to materialize a pull
+// request from a single push instead of a dedicated PR branch and
+// separate CreatePullRequest call. It returns the PR URL parsed from the
+// server's sideband report, if one was included.
+func PushForReview(target, topic, title, body string) (string, error) {
+	if repo == nil {
+		return "", fmt.Errorf("gitops: repository not configured")
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("HEAD:refs/for/%s", target))
+
+	var sideband strings.Builder
+	err := repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       auth,
+		Progress:   &sideband,
+		Options: map[string]string{
+			"topic":       topic,
+			"title":       title,
+			"description": body,
+		},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return "", fmt.Errorf("push HEAD:refs/for/%s: %w", target, err)
+	}
+
+	return parsePRURL(sideband.String()), nil
+}
+
+// parsePRURL extracts a pull request URL from the server's sideband
+// messages, e.g. Gitea's "Visit the link below to create a new pull
+// request:" hint that follows an AGit push.
+func parsePRURL(sideband string) string {
+	for _, line := range strings.Split(sideband, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "remote:")
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+			return line
+		}
+	}
+	return ""
+}
@@ -1,90 +1,316 @@
-// Package gitops provides Git operations via subprocess.
+// Package gitops provides Git operations via an embedded go-git client, so
+// the action can run in minimal container images that don't ship a git
+// binary.
 package gitops
 
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
-// Configure sets up git with user info and authentication.
-func Configure(userName, userEmail, token, repository, serverURL string) error {
-	// Mark workspace as safe before any local git operations
-	cwd, _ := os.Getwd()
-	if err := run("git", "config", "--global", "--add", "safe.directory", cwd); err != nil {
-		return err
+// RepoOpener opens the on-disk repository backing subsequent git
+// operations. Tests can swap in a memfs-backed implementation instead of
+// operating on a real checkout.
+type RepoOpener interface {
+	Open(path string) (*git.Repository, error)
+}
+
+type osOpener struct{}
+
+func (osOpener) Open(path string) (*git.Repository, error) {
+	return git.PlainOpen(path)
+}
+
+// Opener is the RepoOpener used by Configure. Tests may replace it with a
+// memfs-backed repository.
+var Opener RepoOpener = osOpener{}
+
+var (
+	repo      *git.Repository
+	auth      *http.BasicAuth
+	userName  string
+	userEmail string
+
+	// forcePush is set by CreateBranch when reusing an existing PR branch
+	// with force, so the subsequent CommitAndPush doesn't fail
+	// non-fast-forward against the branch's own prior history.
+	forcePush bool
+
+	// amendPrevious makes the next Commit call replace the branch's tip
+	// commit instead of stacking a new one on top of it. See
+	// SetAmendPrevious.
+	amendPrevious bool
+)
+
+// SetAmendPrevious controls whether subsequent Commit/CommitAndPush calls
+// amend the current branch tip rather than adding a new commit on top of
+// it. It's meant for bot-owned PR branches that get pushed to repeatedly
+// across runs, where stacking a fresh commit each time just adds noise.
+func SetAmendPrevious(amend bool) {
+	amendPrevious = amend
+}
+
+// previousCommitParent returns the parent of the branch's current tip
+// commit, so Commit can build a replacement commit in its place. It
+// reports false both for a root commit (no parent to carry forward) and
+// for a tip that isn't one of this bot's own commits - e.g. right after
+// CreateBranch resets the branch onto origin's base tip, where HEAD is
+// someone else's upstream commit and there is nothing of ours to amend.
+// In that case Commit falls back to stacking normally, so the base
+// tip stays in the branch's history instead of being dropped.
+func previousCommitParent(repo *git.Repository) (plumbing.Hash, bool) {
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.Hash{}, false
 	}
 
-	if err := run("git", "config", "user.name", userName); err != nil {
-		return err
+	tip, err := repo.CommitObject(head.Hash())
+	if err != nil || tip.NumParents() == 0 {
+		return plumbing.Hash{}, false
 	}
-	if err := run("git", "config", "user.email", userEmail); err != nil {
-		return err
+
+	if tip.Author.Name != userName || tip.Author.Email != userEmail {
+		return plumbing.Hash{}, false
 	}
 
-	// Set up authenticated remote
-	if token != "" && repository != "" {
-		host := strings.TrimPrefix(strings.TrimPrefix(serverURL, "https://"), "http://")
-		remoteURL := fmt.Sprintf("https://x-access-token:%s@%s/%s.git", token, host, repository)
-		if err := run("git", "remote", "set-url", "origin", remoteURL); err != nil {
-			return err
-		}
+	return tip.ParentHashes[0], true
+}
+
+// Configure opens the workspace repository and records the identity and
+// authentication used for subsequent commits and pushes. Unlike the
+// subprocess git it replaces, it never mutates the on-disk remote URL or
+// global git config.
+func Configure(gitUserName, gitUserEmail, token, repository, serverURL string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getwd: %w", err)
+	}
+
+	r, err := Opener.Open(cwd)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+
+	repo = r
+	userName = gitUserName
+	userEmail = gitUserEmail
+	auth = nil
+
+	if token != "" {
+		auth = &http.BasicAuth{Username: "x-access-token", Password: token}
 	}
 
 	return nil
 }
 
-// GetDefaultBranch returns the default branch name.
+// GetDefaultBranch returns the default branch name, resolved from the
+// symbolic origin/HEAD reference.
 func GetDefaultBranch() string {
-	out, err := output("git", "symbolic-ref", "refs/remotes/origin/HEAD")
+	if repo == nil {
+		return "main"
+	}
+
+	refs, err := repo.References()
 	if err != nil {
 		return "main"
 	}
-	return strings.TrimPrefix(strings.TrimSpace(out), "refs/remotes/origin/")
+
+	branch := "main"
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.SymbolicReference && ref.Name() == plumbing.ReferenceName("refs/remotes/origin/HEAD") {
+			branch = strings.TrimPrefix(ref.Target().Short(), "origin/")
+		}
+		return nil
+	})
+
+	return branch
 }
 
-// CreateBranch creates and checks out a new branch from a base.
-func CreateBranch(name, base string) error {
-	if err := run("git", "fetch", "origin", base); err != nil {
+// CreateBranch fetches base from origin and checks out a new branch from
+// its tip. If a branch named name already exists on origin (e.g. a PR
+// branch reused from a previous run), it either force-resets onto base
+// when force is true, or returns a clear error rather than letting a
+// later push fail opaquely. Any existing branch is treated this way;
+// whether its history actually diverges from base is not checked.
+func CreateBranch(name, base string, force bool) error {
+	if repo == nil {
+		return fmt.Errorf("gitops: repository not configured")
+	}
+
+	if err := fetchBranch(base); err != nil {
 		return err
 	}
-	return run("git", "checkout", "-b", name, "origin/"+base)
+
+	baseRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", base), true)
+	if err != nil {
+		return fmt.Errorf("resolve origin/%s: %w", base, err)
+	}
+
+	exists := remoteBranchExists(name)
+	if exists && !force {
+		return fmt.Errorf("branch %q already exists on origin with its own history; set force-reuse-branch to overwrite it, or choose a different pr-branch", name)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("worktree: %w", err)
+	}
+
+	err = wt.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(name),
+		Hash:   baseRef.Hash(),
+		Create: true,
+		Force:  exists,
+	})
+	if err != nil {
+		return fmt.Errorf("checkout -b %s: %w", name, err)
+	}
+
+	forcePush = exists
+
+	return nil
 }
 
-// CommitAndPush stages files, commits, pushes, and returns the commit SHA.
-func CommitAndPush(files []string, message, branch string) (string, error) {
+// remoteBranchExists reports whether origin already has a branch named
+// name, fetching it first so a stale local view doesn't miss it.
+func remoteBranchExists(name string) bool {
+	if err := fetchBranch(name); err != nil {
+		return false
+	}
+	_, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", name), true)
+	return err == nil
+}
+
+// CommitResult describes the commit produced by CommitAndPush.
+type CommitResult struct {
+	SHA          string
+	Signed       bool
+	SigningKeyID string
+}
+
+// CommitAndPush stages files, commits (optionally signing, see Sign),
+// pushes to branch, and returns the resulting commit.
+func CommitAndPush(files []string, message, branch string) (*CommitResult, error) {
+	result, err := Commit(files, message, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	refSpec := config.RefSpec(fmt.Sprintf("%s:refs/heads/%s", plumbing.NewBranchReferenceName(branch), branch))
+	err = repo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       auth,
+		Force:      forcePush,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, fmt.Errorf("push %s: %w", branch, err)
+	}
+
+	return result, nil
+}
+
+// Commit stages files and commits them to the currently checked-out
+// branch (optionally signing, see Sign), without pushing. branch names
+// the ref to update when a post-hoc signature (SSH) requires rewriting
+// the commit in place.
+func Commit(files []string, message, branch string) (*CommitResult, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("gitops: repository not configured")
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("worktree: %w", err)
+	}
+
 	for _, f := range files {
-		if err := run("git", "add", f); err != nil {
-			return "", err
+		if _, err := wt.Add(f); err != nil {
+			return nil, fmt.Errorf("add %s: %w", f, err)
 		}
 	}
 
-	if err := run("git", "commit", "-m", message); err != nil {
-		return "", err
+	if signing != nil && signing.Signoff {
+		message = appendSignoff(message, userName, userEmail)
 	}
 
-	if err := run("git", "push", "-u", "origin", branch); err != nil {
-		return "", err
+	commitOpts := &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  userName,
+			Email: userEmail,
+			When:  time.Now(),
+		},
+	}
+
+	if amendPrevious {
+		if parent, ok := previousCommitParent(repo); ok {
+			commitOpts.Parents = []plumbing.Hash{parent}
+		}
 	}
 
-	sha, err := output("git", "rev-parse", "HEAD")
+	var keyID string
+	signed := false
+	if signing != nil && signing.Key != "" && signing.Format != "ssh" {
+		entity, id, err := loadOpenPGPEntity(*signing)
+		if err != nil {
+			return nil, fmt.Errorf("load signing key: %w", err)
+		}
+		commitOpts.SignKey = entity
+		keyID = id
+		signed = true
+	}
+
+	hash, err := wt.Commit(message, commitOpts)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("commit: %w", err)
 	}
 
-	return strings.TrimSpace(sha), nil
+	if signing != nil && signing.Key != "" && signing.Format == "ssh" {
+		newHash, id, err := signCommitSSH(repo, hash, *signing)
+		if err != nil {
+			return nil, fmt.Errorf("sign commit with ssh key: %w", err)
+		}
+		if err := updateBranchHead(repo, branch, newHash); err != nil {
+			return nil, fmt.Errorf("update branch head: %w", err)
+		}
+		hash = newHash
+		keyID = id
+		signed = true
+	}
+
+	return &CommitResult{SHA: hash.String(), Signed: signed, SigningKeyID: keyID}, nil
 }
 
-func run(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+func updateBranchHead(repo *git.Repository, branch string, hash plumbing.Hash) error {
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), hash)
+	if err := repo.Storer.SetReference(ref); err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Force: true})
 }
 
-func output(name string, args ...string) (string, error) {
-	cmd := exec.Command(name, args...)
-	out, err := cmd.Output()
-	return string(out), err
+func fetchBranch(branch string) error {
+	refSpec := config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/remotes/origin/%s", branch, branch))
+	err := repo.Fetch(&git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       auth,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("fetch origin %s: %w", branch, err)
+	}
+	return nil
 }
@@ -0,0 +1,234 @@
+package gitops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+type memOpener struct {
+	repo *git.Repository
+}
+
+func (m memOpener) Open(path string) (*git.Repository, error) {
+	return m.repo, nil
+}
+
+// newTestRepo builds an in-memory repository with a single commit on
+// its default branch, recorded as refs/remotes/origin/main, and a
+// refs/remotes/origin/HEAD pointing at it, mimicking what a checkout
+// action leaves behind.
+func newTestRepo(t *testing.T) *git.Repository {
+	t.Helper()
+
+	fs := memfs.New()
+	repo, err := git.Init(memory.NewStorage(), fs)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	f, err := fs.Create("README.md")
+	if err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	_, _ = f.Write([]byte("hello\n"))
+	_ = f.Close()
+
+	if _, err := wt.Add("README.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	hash, err := wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/origin/main", hash)); err != nil {
+		t.Fatalf("set origin/main: %v", err)
+	}
+	head := plumbing.NewSymbolicReference("refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	if err := repo.Storer.SetReference(head); err != nil {
+		t.Fatalf("set origin/HEAD: %v", err)
+	}
+
+	return repo
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	t.Run("resolves origin/HEAD", func(t *testing.T) {
+		Opener = memOpener{repo: newTestRepo(t)}
+		defer func() { Opener = osOpener{} }()
+
+		if err := Configure("bot", "bot@example.com", "", "", ""); err != nil {
+			t.Fatalf("Configure: %v", err)
+		}
+
+		if got := GetDefaultBranch(); got != "main" {
+			t.Errorf("GetDefaultBranch() = %q, want %q", got, "main")
+		}
+	})
+
+	t.Run("falls back when unconfigured", func(t *testing.T) {
+		repo = nil
+
+		if got := GetDefaultBranch(); got != "main" {
+			t.Errorf("GetDefaultBranch() = %q, want %q", got, "main")
+		}
+	})
+}
+
+func TestCreateBranchRequiresConfigure(t *testing.T) {
+	repo = nil
+
+	if err := CreateBranch("feature", "main", false); err == nil {
+		t.Error("expected error when repository is not configured")
+	}
+}
+
+func TestCommitAndPushRequiresConfigure(t *testing.T) {
+	repo = nil
+
+	if _, err := CommitAndPush([]string{"file.yaml"}, "msg", "feature"); err == nil {
+		t.Error("expected error when repository is not configured")
+	}
+}
+
+func TestCommitAmendsPreviousWhenEnabled(t *testing.T) {
+	r := newTestRepo(t)
+	Opener = memOpener{repo: r}
+	defer func() { Opener = osOpener{}; amendPrevious = false }()
+
+	if err := Configure("bot", "bot@example.com", "", "", ""); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+
+	initialHead, err := r.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	initialHash := initialHead.Hash()
+	branch := initialHead.Name().Short()
+
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	writeFile := func(content string) {
+		f, err := wt.Filesystem.Create("bot.yaml")
+		if err != nil {
+			t.Fatalf("create file: %v", err)
+		}
+		_, _ = f.Write([]byte(content))
+		_ = f.Close()
+	}
+
+	writeFile("v1\n")
+	firstBot, err := Commit([]string{"bot.yaml"}, "bot: bump v1", branch)
+	if err != nil {
+		t.Fatalf("first Commit: %v", err)
+	}
+
+	SetAmendPrevious(true)
+
+	writeFile("v2\n")
+	secondBot, err := Commit([]string{"bot.yaml"}, "bot: bump v2", branch)
+	if err != nil {
+		t.Fatalf("second Commit: %v", err)
+	}
+
+	if secondBot.SHA == firstBot.SHA {
+		t.Fatal("expected a new commit hash")
+	}
+
+	amended, err := r.CommitObject(plumbing.NewHash(secondBot.SHA))
+	if err != nil {
+		t.Fatalf("CommitObject: %v", err)
+	}
+	if amended.NumParents() != 1 || amended.ParentHashes[0] != initialHash {
+		t.Errorf("expected the amended commit's parent to be the initial commit %s, got %v", initialHash, amended.ParentHashes)
+	}
+
+	log, err := r.Log(&git.LogOptions{From: plumbing.NewHash(secondBot.SHA)})
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	count := 0
+	_ = log.ForEach(func(*object.Commit) error { count++; return nil })
+	if count != 2 {
+		t.Errorf("expected 2 commits in history after amend (initial + amended bot commit), got %d", count)
+	}
+}
+
+// TestCommitAmendAfterCreateBranchKeepsBaseTip reproduces the reused-PR-
+// branch flow end to end: CreateBranch always resets the branch onto
+// origin's current base tip, so with amend-previous enabled, HEAD right
+// before the first Commit of a run is someone else's upstream commit,
+// not a bot commit from an earlier run. Amend must not mistake that tip
+// for "the previous bot commit" and drop it from history.
+func TestCommitAmendAfterCreateBranchKeepsBaseTip(t *testing.T) {
+	remoteDir := t.TempDir()
+	initOnDisk(t, remoteDir, true)
+
+	localDir := t.TempDir()
+	local := initOnDisk(t, localDir, false)
+	if _, err := local.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}}); err != nil {
+		t.Fatalf("create remote: %v", err)
+	}
+
+	commitFile(t, local, "README.md", "hello\n", "initial")
+	mainTip := commitFile(t, local, "CHANGELOG.md", "v1\n", "upstream change")
+
+	if err := local.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{"refs/heads/main:refs/heads/main"}}); err != nil {
+		t.Fatalf("seed push: %v", err)
+	}
+
+	Opener = memOpener{repo: local}
+	defer func() { Opener = osOpener{} }()
+	if err := Configure("bot", "bot@example.com", "", "", ""); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+
+	if err := CreateBranch("pr-branch", "main", false); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+
+	SetAmendPrevious(true)
+	defer func() { amendPrevious = false }()
+
+	wt, err := local.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	f, err := wt.Filesystem.Create("bot.yaml")
+	if err != nil {
+		t.Fatalf("create bot.yaml: %v", err)
+	}
+	_, _ = f.Write([]byte("v1\n"))
+	_ = f.Close()
+
+	result, err := Commit([]string{"bot.yaml"}, "bot: update", "pr-branch")
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	commit, err := local.CommitObject(plumbing.NewHash(result.SHA))
+	if err != nil {
+		t.Fatalf("CommitObject: %v", err)
+	}
+	if commit.NumParents() != 1 || commit.ParentHashes[0] != mainTip {
+		t.Errorf("expected the bot commit's parent to be main's tip %s, got %v", mainTip, commit.ParentHashes)
+	}
+}
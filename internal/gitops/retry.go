@@ -0,0 +1,85 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// MaxPushRetries is the default number of times CommitAndPushWithRetry
+// will resync onto the moved remote tip and retry before giving up.
+const MaxPushRetries = 3
+
+// Regenerator re-applies the caller's update logic against the
+// just-resynced working tree and returns the list of files it changed.
+// It's supplied by the caller (cmd/yaml-update) because gitops has no
+// knowledge of what an update actually does to a file.
+type Regenerator func() ([]string, error)
+
+// CommitAndPushWithRetry behaves like CommitAndPush, but if the push is
+// rejected because branch moved on origin since CreateBranch ran (e.g. a
+// concurrent workflow run raced us), it resets the branch onto the new
+// remote tip, calls regenerate to redo the update against the fresh file
+// contents, and retries the commit and push. It gives up after
+// MaxPushRetries retries, returning the last push error. The int result
+// is the number of retries actually performed, for callers that want to
+// surface it as an output.
+func CommitAndPushWithRetry(files []string, message, branch string, regenerate Regenerator) (*CommitResult, int, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := CommitAndPush(files, message, branch)
+		if err == nil {
+			return result, attempt, nil
+		}
+		if !isNonFastForward(err) || attempt >= MaxPushRetries {
+			return nil, attempt, err
+		}
+
+		if err := resyncBranch(branch); err != nil {
+			return nil, attempt, fmt.Errorf("resync onto origin/%s after push conflict: %w", branch, err)
+		}
+
+		files, err = regenerate()
+		if err != nil {
+			return nil, attempt, fmt.Errorf("regenerate after resync: %w", err)
+		}
+		if len(files) == 0 {
+			return nil, attempt, fmt.Errorf("no changes remain after resyncing onto origin/%s; the update was already applied upstream", branch)
+		}
+	}
+}
+
+// isNonFastForward reports whether err is (or wraps) go-git's rejection
+// for a push whose local branch has fallen behind origin. Repository.Push
+// returns this as a plain fmt.Errorf("non-fast-forward update: %s", ...)
+// rather than wrapping git.ErrNonFastForwardUpdate (that sentinel is only
+// ever returned by Worktree.Pull), so this has to match on the message.
+func isNonFastForward(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, git.ErrNonFastForwardUpdate) || strings.Contains(err.Error(), "non-fast-forward update")
+}
+
+// resyncBranch fetches branch from origin and hard-resets the local
+// branch and worktree onto its new tip, discarding the orphaned local
+// commit that CommitAndPush made before the push was rejected.
+func resyncBranch(branch string) error {
+	if err := fetchBranch(branch); err != nil {
+		return err
+	}
+
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
+	if err != nil {
+		return fmt.Errorf("resolve origin/%s: %w", branch, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("worktree: %w", err)
+	}
+
+	return wt.Reset(&git.ResetOptions{Commit: remoteRef.Hash(), Mode: git.HardReset})
+}
@@ -0,0 +1,184 @@
+package gitops
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestIsNonFastForward(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"non-fast-forward", git.ErrNonFastForwardUpdate, true},
+		{"wrapped non-fast-forward", fmt.Errorf("push branch: %w", git.ErrNonFastForwardUpdate), true},
+		{"unwrapped push rejection", fmt.Errorf("non-fast-forward update: refs/heads/feature"), true},
+		{"unrelated error", fmt.Errorf("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNonFastForward(tt.err); got != tt.want {
+				t.Errorf("isNonFastForward(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// initOnDisk creates a real (non-bare unless bare is set) on-disk
+// repository rooted at "main", so pushes between it and other on-disk
+// repos go over go-git's "file" transport instead of the memfs-only,
+// refs-set-directly harness used elsewhere in this package - that's
+// needed here to get a genuine rejected push out of Repository.Push
+// rather than a synthetic error.
+func initOnDisk(t *testing.T, dir string, bare bool) *git.Repository {
+	t.Helper()
+
+	r, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		Bare:        bare,
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	if err != nil {
+		t.Fatalf("init %s: %v", dir, err)
+	}
+	return r
+}
+
+func commitFile(t *testing.T, r *git.Repository, name, content, message string) plumbing.Hash {
+	t.Helper()
+
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	f, err := wt.Filesystem.Create(name)
+	if err != nil {
+		t.Fatalf("create %s: %v", name, err)
+	}
+	_, _ = f.Write([]byte(content))
+	_ = f.Close()
+
+	if _, err := wt.Add(name); err != nil {
+		t.Fatalf("add %s: %v", name, err)
+	}
+
+	hash, err := wt.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	return hash
+}
+
+// TestCommitAndPushWithRetryResyncsOnRealPushRejection drives a genuine
+// non-fast-forward push rejection through CommitAndPushWithRetry, rather
+// than a synthetic error: a second clone pushes to "feature" on a shared
+// bare remote behind our back, so our own push lands on a stale tip and
+// go-git's Repository.Push rejects it with its real, unwrapped
+// "non-fast-forward update: ..." error.
+func TestCommitAndPushWithRetryResyncsOnRealPushRejection(t *testing.T) {
+	remoteDir := t.TempDir()
+	initOnDisk(t, remoteDir, true)
+
+	localDir := t.TempDir()
+	local := initOnDisk(t, localDir, false)
+	if _, err := local.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}}); err != nil {
+		t.Fatalf("create remote: %v", err)
+	}
+
+	localWT, err := local.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	commitFile(t, local, "README.md", "hello\n", "initial")
+	if err := localWT.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatalf("checkout -b feature: %v", err)
+	}
+	commitFile(t, local, "bot.yaml", "v1\n", "seed bot.yaml")
+
+	push := func(r *git.Repository) error {
+		return r.Push(&git.PushOptions{
+			RemoteName: "origin",
+			RefSpecs:   []config.RefSpec{"refs/heads/feature:refs/heads/feature"},
+		})
+	}
+	if err := push(local); err != nil {
+		t.Fatalf("seed push: %v", err)
+	}
+
+	otherDir := t.TempDir()
+	other, err := git.PlainClone(otherDir, false, &git.CloneOptions{URL: remoteDir, ReferenceName: plumbing.NewBranchReferenceName("feature")})
+	if err != nil {
+		t.Fatalf("clone other: %v", err)
+	}
+	commitFile(t, other, "rival.yaml", "mine\n", "rival: concurrent change")
+	if err := push(other); err != nil {
+		t.Fatalf("rival push: %v", err)
+	}
+
+	Opener = memOpener{repo: local}
+	defer func() { Opener = osOpener{} }()
+	if err := Configure("bot", "bot@example.com", "", "", ""); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+
+	writeBotYAML := func(content string) []string {
+		f, err := localWT.Filesystem.Create("bot.yaml")
+		if err != nil {
+			t.Fatalf("create bot.yaml: %v", err)
+		}
+		_, _ = f.Write([]byte(content))
+		_ = f.Close()
+		return []string{"bot.yaml"}
+	}
+
+	regenerated := false
+	regenerate := func() ([]string, error) {
+		regenerated = true
+		return writeBotYAML("v2\n"), nil
+	}
+
+	writeBotYAML("stale\n")
+	result, retries, err := CommitAndPushWithRetry([]string{"bot.yaml"}, "bot: bump", "feature", regenerate)
+	if err != nil {
+		t.Fatalf("CommitAndPushWithRetry: %v", err)
+	}
+	if retries != 1 {
+		t.Errorf("retries = %d, want 1", retries)
+	}
+	if !regenerated {
+		t.Error("expected regenerate to be called after the rejected push")
+	}
+	if result == nil || result.SHA == "" {
+		t.Fatal("expected a commit result")
+	}
+
+	head, err := local.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	commit, err := local.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("commit object: %v", err)
+	}
+	if commit.NumParents() != 1 {
+		t.Fatalf("expected retried commit to have one parent, got %d", commit.NumParents())
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		t.Fatalf("parent: %v", err)
+	}
+	if parent.Message != "rival: concurrent change" {
+		t.Errorf("retried commit's parent = %q, want the resynced rival commit", parent.Message)
+	}
+}
@@ -0,0 +1,232 @@
+package gitops
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshsigMagicPreamble, sshsigVersion, sshsigNamespace and
+// sshsigHashAlgorithm are fixed by the openssh PROTOCOL.sshsig format
+// (https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig)
+// that `ssh-keygen -Y sign/verify` and git's gpg.format=ssh both speak.
+// namespace "git" is what git itself signs and verifies with.
+const (
+	sshsigMagicPreamble = "SSHSIG"
+	sshsigVersion       = 1
+	sshsigNamespace     = "git"
+	sshsigHashAlgorithm = "sha256"
+)
+
+// SigningOptions configures commit signing for CommitAndPush, mirroring
+// git's gpg.format=openpgp|ssh and commit.gpgsign/sign-off behavior.
+type SigningOptions struct {
+	// Key is an armored PGP private key or an SSH private key, given
+	// inline or as a path to one.
+	Key string
+	// Passphrase decrypts Key, if it is encrypted.
+	Passphrase string
+	// Format is "openpgp" (the default) or "ssh".
+	Format string
+	// Signoff appends a Signed-off-by trailer to the commit message.
+	Signoff bool
+}
+
+var signing *SigningOptions
+
+// Sign records the signing configuration applied by subsequent calls to
+// CommitAndPush. Pass nil to disable signing.
+func Sign(opts *SigningOptions) {
+	signing = opts
+}
+
+func appendSignoff(message, name, email string) string {
+	trailer := fmt.Sprintf("Signed-off-by: %s <%s>", name, email)
+	if strings.Contains(message, trailer) {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + trailer
+}
+
+func readKeyMaterial(key string) ([]byte, error) {
+	if data, err := os.ReadFile(key); err == nil {
+		return data, nil
+	}
+	return []byte(key), nil
+}
+
+// loadOpenPGPEntity decodes opts.Key into an openpgp.Entity suitable for
+// git.CommitOptions.SignKey, decrypting the private key if needed.
+func loadOpenPGPEntity(opts SigningOptions) (*openpgp.Entity, string, error) {
+	data, err := readKeyMaterial(opts.Key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	entityList, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("read armored key ring: %w", err)
+	}
+	if len(entityList) == 0 {
+		return nil, "", fmt.Errorf("no key found in signing-key input")
+	}
+
+	entity := entityList[0]
+	if entity.PrivateKey != nil && entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt([]byte(opts.Passphrase)); err != nil {
+			return nil, "", fmt.Errorf("decrypt private key: %w", err)
+		}
+	}
+
+	return entity, entity.PrimaryKey.KeyIdString(), nil
+}
+
+// signCommitSSH re-signs the commit at hash using an SSH signing key,
+// since go-git's CommitOptions only signs with OpenPGP natively. It
+// rewrites the commit with a gpgsig field containing an SSH SIGNATURE
+// block per git's gpg.format=ssh / ssh-keygen -Y sign convention, stores
+// the new object, and returns its hash and the signing key's SHA256
+// fingerprint (the closest SSH equivalent of a PGP key ID).
+func signCommitSSH(repo *git.Repository, hash plumbing.Hash, opts SigningOptions) (plumbing.Hash, string, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("load commit: %w", err)
+	}
+
+	data, err := readKeyMaterial(opts.Key)
+	if err != nil {
+		return plumbing.ZeroHash, "", err
+	}
+
+	var signer ssh.Signer
+	if opts.Passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(opts.Passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(data)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("parse ssh signing key: %w", err)
+	}
+
+	payload, err := encodeCommitForSigning(commit)
+	if err != nil {
+		return plumbing.ZeroHash, "", err
+	}
+	digest := sha256.Sum256(payload)
+
+	sig, err := signer.Sign(rand.Reader, sshsigSignedData(digest[:]))
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("sign with ssh key: %w", err)
+	}
+
+	commit.PGPSignature = sshSignatureArmor(sig, signer.PublicKey())
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("encode signed commit: %w", err)
+	}
+
+	newHash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("store signed commit: %w", err)
+	}
+
+	return newHash, ssh.FingerprintSHA256(signer.PublicKey()), nil
+}
+
+// encodeCommitForSigning renders a commit without its gpgsig field, which
+// is exactly the payload git signs and verifies over.
+func encodeCommitForSigning(commit *object.Commit) ([]byte, error) {
+	unsigned := *commit
+	unsigned.PGPSignature = ""
+
+	obj := &plumbing.MemoryObject{}
+	if err := unsigned.Encode(obj); err != nil {
+		return nil, fmt.Errorf("encode commit payload: %w", err)
+	}
+
+	r, err := obj.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("read encoded commit: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("buffer encoded commit: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sshsigSignedData builds the exact byte sequence PROTOCOL.sshsig
+// requires signers to sign over: the literal "SSHSIG" preamble
+// (unlike every other field here, not length-prefixed) followed by the
+// wire-encoded namespace, a reserved empty string, the hash algorithm
+// name, and the digest itself. git signs and verifies over this, not
+// the raw commit bytes.
+func sshsigSignedData(digest []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(sshsigMagicPreamble)
+	buf.Write(sshWireString([]byte(sshsigNamespace)))
+	buf.Write(sshWireString(nil))
+	buf.Write(sshWireString([]byte(sshsigHashAlgorithm)))
+	buf.Write(sshWireString(digest))
+	return buf.Bytes()
+}
+
+// sshSignatureArmor wraps sig in the ASCII-armored PROTOCOL.sshsig
+// envelope produced by `ssh-keygen -Y sign -n git`, which is what git
+// expects in a commit's gpgsig header for gpg.format=ssh: the preamble,
+// a version, the signer's public key, namespace, reserved field, hash
+// algorithm, and finally the wire-encoded signature itself - not just a
+// bare marshaled Signature. The digest isn't repeated here: a verifier
+// recomputes H(message) itself from the data it's checking and folds it
+// into the same signed-data blob sshsigSignedData builds.
+func sshSignatureArmor(sig *ssh.Signature, pub ssh.PublicKey) string {
+	var blob bytes.Buffer
+	blob.WriteString(sshsigMagicPreamble)
+	var version [4]byte
+	binary.BigEndian.PutUint32(version[:], sshsigVersion)
+	blob.Write(version[:])
+	blob.Write(sshWireString(pub.Marshal()))
+	blob.Write(sshWireString([]byte(sshsigNamespace)))
+	blob.Write(sshWireString(nil))
+	blob.Write(sshWireString([]byte(sshsigHashAlgorithm)))
+	blob.Write(sshWireString(ssh.Marshal(sig)))
+
+	encoded := base64.StdEncoding.EncodeToString(blob.Bytes())
+
+	var buf strings.Builder
+	buf.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 0 {
+		n := 76
+		if n > len(encoded) {
+			n = len(encoded)
+		}
+		buf.WriteString(encoded[:n])
+		buf.WriteByte('\n')
+		encoded = encoded[n:]
+	}
+	buf.WriteString("-----END SSH SIGNATURE-----\n")
+	return buf.String()
+}
+
+// sshWireString encodes b as an SSH wire-format "string": a four-byte
+// big-endian length prefix followed by the raw bytes.
+func sshWireString(b []byte) []byte {
+	out := make([]byte, 4+len(b))
+	binary.BigEndian.PutUint32(out, uint32(len(b)))
+	copy(out[4:], b)
+	return out
+}
@@ -0,0 +1,74 @@
+package gitops
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// TestSSHSignatureVerifiesWithSSHKeygen confirms the armored blob
+// signCommitSSH produces is a real PROTOCOL.sshsig signature that
+// OpenSSH itself accepts, by shelling out to `ssh-keygen -Y verify` -
+// the same check `git verify-commit` and forge-side "require signed
+// commits" branch protection ultimately perform.
+func TestSSHSignatureVerifiesWithSSHKeygen(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if out, err := exec.Command("ssh-keygen", "-t", "ed25519", "-N", "", "-C", "bot@example.com", "-f", keyPath, "-q").CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen -t ed25519: %v\n%s", err, out)
+	}
+
+	keyData, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("read private key: %v", err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyData)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+
+	message := []byte("tree deadbeef\nauthor bot <bot@example.com> 0 +0000\n\nbot: bump\n")
+	digest := sha256.Sum256(message)
+
+	sig, err := signer.Sign(rand.Reader, sshsigSignedData(digest[:]))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	armored := sshSignatureArmor(sig, signer.PublicKey())
+
+	sigPath := filepath.Join(dir, "sig.asc")
+	if err := os.WriteFile(sigPath, []byte(armored), 0o600); err != nil {
+		t.Fatalf("write signature: %v", err)
+	}
+
+	pubKeyLine, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatalf("read public key: %v", err)
+	}
+	allowedSignersPath := filepath.Join(dir, "allowed_signers")
+	allowedSigners := "bot@example.com " + string(pubKeyLine)
+	if err := os.WriteFile(allowedSignersPath, []byte(allowedSigners), 0o600); err != nil {
+		t.Fatalf("write allowed_signers: %v", err)
+	}
+
+	cmd := exec.Command("ssh-keygen", "-Y", "verify",
+		"-f", allowedSignersPath,
+		"-I", "bot@example.com",
+		"-n", sshsigNamespace,
+		"-s", sigPath,
+	)
+	cmd.Stdin = bytes.NewReader(message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen -Y verify: %v\n%s", err, out)
+	}
+}
@@ -5,71 +5,100 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/dnd-it/action-yaml-update/internal/updater"
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds all parsed input values.
 type Config struct {
-	Files          []string
-	Mode           string
-	Keys           []string
-	Values         []string
-	ImageName      string
-	ImageTag       string
-	CreatePR       bool
-	TargetBranch   string
-	PRBranch       string
-	PRTitle        string
-	PRBody         string
-	PRLabels       []string
-	PRReviewers    []string
-	CommitMessage  string
-	Token          string
-	AutoMerge      bool
-	MergeMethod    string
-	DryRun         bool
-	GitUserName    string
-	GitUserEmail   string
-	GithubRepo     string
-	GithubServerURL string
-	GithubAPIURL   string
-	GithubGraphQLURL string
+	Files                []string
+	Mode                 string
+	Keys                 []string
+	Values               []string
+	ImageName            string
+	ImageTag             string
+	Images               []updater.ImageSpec
+	CreatePR             bool
+	TargetBranch         string
+	PRBranch             string
+	PRTitle              string
+	PRBody               string
+	PRLabels             []string
+	PRReviewers          []string
+	CommitMessage        string
+	Token                string
+	AutoMerge            bool
+	MergeMethod          string
+	DryRun               bool
+	GitUserName          string
+	GitUserEmail         string
+	GithubRepo           string
+	GithubServerURL      string
+	GithubAPIURL         string
+	GithubGraphQLURL     string
+	SigningKey           string
+	SigningKeyPassphrase string
+	SigningFormat        string
+	Signoff              bool
+	Forge                string
+	ForgeURL             string
+	UseAGit              bool
+	ManifestPath         string
+	ForceReuseBranch     bool
+	OverlaySuffix        string
+	WriteToOverlay       bool
+	AmendPrevious        bool
 }
 
 // Parse reads and validates inputs from environment variables.
 func Parse() (*Config, error) {
 	cfg := &Config{
-		Mode:           getEnv("MODE", "key"),
-		CreatePR:       parseBool(getEnv("CREATE_PR", "true")),
-		TargetBranch:   getEnv("TARGET_BRANCH", ""),
-		PRBranch:       getEnv("PR_BRANCH", ""),
-		PRTitle:        getEnv("PR_TITLE", "chore: update YAML values"),
-		PRBody:         getEnv("PR_BODY", ""),
-		CommitMessage:  getEnv("COMMIT_MESSAGE", "chore: update YAML values"),
-		Token:          getEnvFallback("TOKEN", "GITHUB_TOKEN", ""),
-		AutoMerge:      parseBool(getEnv("AUTO_MERGE", "false")),
-		MergeMethod:    getEnv("MERGE_METHOD", "SQUASH"),
-		DryRun:         parseBool(getEnv("DRY_RUN", "false")),
-		GitUserName:    getEnv("GIT_USER_NAME", "github-actions[bot]"),
-		GitUserEmail:   getEnv("GIT_USER_EMAIL", "41898282+github-actions[bot]@users.noreply.github.com"),
-		GithubRepo:     os.Getenv("GITHUB_REPOSITORY"),
-		GithubServerURL: getEnvDefault("GITHUB_SERVER_URL", "https://github.com"),
-		GithubAPIURL:   getEnvDefault("GITHUB_API_URL", "https://api.github.com"),
-		GithubGraphQLURL: getEnvDefault("GITHUB_GRAPHQL_URL", "https://api.github.com/graphql"),
-	}
-
-	// Parse files
-	cfg.Files = parseList(getEnv("FILES", ""), "\n")
-	if len(cfg.Files) == 0 {
-		return nil, fmt.Errorf("'files' input is required")
+		Mode:                 getEnv("MODE", "key"),
+		CreatePR:             parseBool(getEnv("CREATE_PR", "true")),
+		TargetBranch:         getEnv("TARGET_BRANCH", ""),
+		PRBranch:             getEnv("PR_BRANCH", ""),
+		PRTitle:              getEnv("PR_TITLE", "chore: update YAML values"),
+		PRBody:               getEnv("PR_BODY", ""),
+		CommitMessage:        getEnv("COMMIT_MESSAGE", "chore: update YAML values"),
+		Token:                getEnvFallback("TOKEN", "GITHUB_TOKEN", ""),
+		AutoMerge:            parseBool(getEnv("AUTO_MERGE", "false")),
+		MergeMethod:          getEnv("MERGE_METHOD", "SQUASH"),
+		DryRun:               parseBool(getEnv("DRY_RUN", "false")),
+		GitUserName:          getEnv("GIT_USER_NAME", "github-actions[bot]"),
+		GitUserEmail:         getEnv("GIT_USER_EMAIL", "41898282+github-actions[bot]@users.noreply.github.com"),
+		GithubRepo:           os.Getenv("GITHUB_REPOSITORY"),
+		GithubServerURL:      getEnvDefault("GITHUB_SERVER_URL", "https://github.com"),
+		GithubAPIURL:         getEnvDefault("GITHUB_API_URL", "https://api.github.com"),
+		GithubGraphQLURL:     getEnvDefault("GITHUB_GRAPHQL_URL", "https://api.github.com/graphql"),
+		SigningKey:           getEnv("SIGNING_KEY", ""),
+		SigningKeyPassphrase: getEnv("SIGNING_KEY_PASSPHRASE", ""),
+		SigningFormat:        getEnv("SIGNING_FORMAT", "openpgp"),
+		Signoff:              parseBool(getEnv("SIGNOFF", "false")),
+		Forge:                getEnv("FORGE", ""),
+		ForgeURL:             getEnvFallback("FORGE_URL", "CI_SERVER_URL", getEnvDefault("GITEA_SERVER_URL", "")),
+		UseAGit:              parseBool(getEnv("USE_AGIT", "false")),
+		ForceReuseBranch:     parseBool(getEnv("FORCE_REUSE_BRANCH", "false")),
+		OverlaySuffix:        getEnv("OVERLAY_SUFFIX", ".local"),
+		WriteToOverlay:       parseBool(getEnv("WRITE_TO_OVERLAY", "false")),
+		AmendPrevious:        parseBool(getEnv("AMEND_PREVIOUS", "false")),
 	}
 
 	// Validate mode
-	if cfg.Mode != "key" && cfg.Mode != "image" {
-		return nil, fmt.Errorf("invalid mode '%s'. Must be 'key' or 'image'", cfg.Mode)
+	if cfg.Mode != "key" && cfg.Mode != "image" && cfg.Mode != "manifest" {
+		return nil, fmt.Errorf("invalid mode '%s'. Must be 'key', 'image', or 'manifest'", cfg.Mode)
 	}
 
 	// Parse mode-specific inputs
-	if cfg.Mode == "key" {
+	switch cfg.Mode {
+	case "manifest":
+		cfg.ManifestPath = getEnv("MANIFEST_PATH", ".github/yaml-updates.yml")
+	case "key":
+		cfg.Files = parseList(getEnv("FILES", ""), "\n")
+		if len(cfg.Files) == 0 {
+			return nil, fmt.Errorf("'files' input is required")
+		}
+
 		cfg.Keys = parseList(getEnv("KEYS", ""), "\n")
 		cfg.Values = parseList(getEnv("VALUES", ""), "\n")
 
@@ -82,15 +111,28 @@ func Parse() (*Config, error) {
 		if len(cfg.Keys) != len(cfg.Values) {
 			return nil, fmt.Errorf("number of keys (%d) must match number of values (%d)", len(cfg.Keys), len(cfg.Values))
 		}
-	} else {
+	default: // "image"
+		cfg.Files = parseList(getEnv("FILES", ""), "\n")
+		if len(cfg.Files) == 0 {
+			return nil, fmt.Errorf("'files' input is required")
+		}
+
 		cfg.ImageName = getEnv("IMAGE_NAME", "")
 		cfg.ImageTag = getEnv("IMAGE_TAG", "")
 
-		if cfg.ImageName == "" {
-			return nil, fmt.Errorf("'image_name' input is required for mode=image")
+		images, err := parseImages(getEnv("IMAGES", ""))
+		if err != nil {
+			return nil, err
 		}
-		if cfg.ImageTag == "" {
-			return nil, fmt.Errorf("'image_tag' input is required for mode=image")
+		cfg.Images = images
+
+		if len(cfg.Images) == 0 {
+			if cfg.ImageName == "" {
+				return nil, fmt.Errorf("'image_name' input is required for mode=image")
+			}
+			if cfg.ImageTag == "" {
+				return nil, fmt.Errorf("'image_tag' input is required for mode=image")
+			}
 		}
 	}
 
@@ -98,6 +140,14 @@ func Parse() (*Config, error) {
 	cfg.PRLabels = parseList(getEnv("PR_LABELS", ""), ",")
 	cfg.PRReviewers = parseList(getEnv("PR_REVIEWERS", ""), ",")
 
+	if cfg.SigningFormat != "openpgp" && cfg.SigningFormat != "ssh" {
+		return nil, fmt.Errorf("invalid signing-format '%s'. Must be 'openpgp' or 'ssh'", cfg.SigningFormat)
+	}
+
+	if cfg.Forge != "" && cfg.Forge != "github" && cfg.Forge != "gitea" && cfg.Forge != "gitlab" {
+		return nil, fmt.Errorf("invalid forge '%s'. Must be 'github', 'gitea', or 'gitlab'", cfg.Forge)
+	}
+
 	return cfg, nil
 }
 
@@ -131,6 +181,27 @@ func parseBool(s string) bool {
 	return lower == "true" || lower == "yes" || lower == "1"
 }
 
+// parseImages decodes the repeatable IMAGES input, a YAML (or JSON, which
+// is valid YAML) list of image specs, into []updater.ImageSpec.
+func parseImages(s string) ([]updater.ImageSpec, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var specs []updater.ImageSpec
+	if err := yaml.Unmarshal([]byte(s), &specs); err != nil {
+		return nil, fmt.Errorf("parse 'images' input: %w", err)
+	}
+
+	for _, spec := range specs {
+		if err := spec.Validate(); err != nil {
+			return nil, fmt.Errorf("'images' input: %w", err)
+		}
+	}
+
+	return specs, nil
+}
+
 func parseList(s, sep string) []string {
 	if strings.TrimSpace(s) == "" {
 		return nil
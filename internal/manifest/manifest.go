@@ -0,0 +1,126 @@
+// Package manifest parses a declarative, Dependabot-style update manifest
+// describing many key/image updates to run in a single action
+// invocation, instead of one workflow job per update.
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Entry describes one set of updates to apply across a glob of files.
+type Entry struct {
+	Files        []string `yaml:"files"`
+	Type         string   `yaml:"type"` // "key" or "image"
+	Keys         []string `yaml:"keys"`
+	Values       []string `yaml:"values"`
+	ImageName    string   `yaml:"image-name"`
+	ImageTag     string   `yaml:"image-tag"`
+	PRTitle      string   `yaml:"pr-title"`
+	PRBody       string   `yaml:"pr-body"`
+	Labels       []string `yaml:"labels"`
+	Reviewers    []string `yaml:"reviewers"`
+	BranchPrefix string   `yaml:"branch-prefix"`
+	Group        string   `yaml:"group"`
+}
+
+// Manifest is the top-level document pointed at by the `manifest-path`
+// input, e.g. `.github/yaml-updates.yml`.
+type Manifest struct {
+	Updates []Entry `yaml:"updates"`
+}
+
+// Load reads and validates a manifest file.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", path, err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+
+	if len(m.Updates) == 0 {
+		return nil, fmt.Errorf("manifest %s: 'updates' is required and must not be empty", path)
+	}
+
+	for i, e := range m.Updates {
+		if e.Type != "key" && e.Type != "image" {
+			return nil, fmt.Errorf("manifest %s: updates[%d].type must be 'key' or 'image', got %q", path, i, e.Type)
+		}
+		if len(e.Files) == 0 {
+			return nil, fmt.Errorf("manifest %s: updates[%d].files is required", path, i)
+		}
+		if e.Type == "key" && (len(e.Keys) == 0 || len(e.Keys) != len(e.Values)) {
+			return nil, fmt.Errorf("manifest %s: updates[%d] must have matching non-empty keys/values for type=key", path, i)
+		}
+		if e.Type == "image" && e.ImageName == "" {
+			return nil, fmt.Errorf("manifest %s: updates[%d].image-name is required for type=image", path, i)
+		}
+	}
+
+	return &m, nil
+}
+
+// ExpandFiles resolves the entry's file globs, relative to the working
+// directory, returning matches in order with duplicates removed.
+func (e Entry) ExpandFiles() ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+
+	for _, pattern := range e.Files {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("expand glob %q: %w", pattern, err)
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// Group is a named set of entries that should land in a single pull
+// request together.
+type Group struct {
+	Name    string
+	Entries []Entry
+}
+
+// Groups partitions the manifest's entries by their `group` field.
+// Entries without a group each get their own single-entry group so they
+// still produce a pull request.
+func (m *Manifest) Groups() []Group {
+	order := make([]string, 0, len(m.Updates))
+	byName := make(map[string]*Group)
+
+	for i, e := range m.Updates {
+		name := e.Group
+		if name == "" {
+			name = fmt.Sprintf("entry-%d", i)
+		}
+
+		g, ok := byName[name]
+		if !ok {
+			g = &Group{Name: name}
+			byName[name] = g
+			order = append(order, name)
+		}
+		g.Entries = append(g.Entries, e)
+	}
+
+	groups := make([]Group, 0, len(order))
+	for _, name := range order {
+		groups = append(groups, *byName[name])
+	}
+	return groups
+}
@@ -0,0 +1,112 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeManifest(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "yaml-updates.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("valid manifest", func(t *testing.T) {
+		path := writeManifest(t, dir, `
+updates:
+  - files: ["a.yaml"]
+    type: key
+    keys: ["app.version"]
+    values: ["v2.0.0"]
+  - files: ["b.yaml"]
+    type: image
+    image-name: webapp
+    image-tag: v2.0.0
+    group: bumps
+`)
+		m, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if len(m.Updates) != 2 {
+			t.Fatalf("got %d updates, want 2", len(m.Updates))
+		}
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		path := writeManifest(t, dir, `
+updates:
+  - files: ["a.yaml"]
+    keys: ["x"]
+    values: ["y"]
+`)
+		if _, err := Load(path); err == nil {
+			t.Error("expected error for missing type")
+		}
+	})
+
+	t.Run("key entry without matching values", func(t *testing.T) {
+		path := writeManifest(t, dir, `
+updates:
+  - files: ["a.yaml"]
+    type: key
+    keys: ["x", "y"]
+    values: ["only-one"]
+`)
+		if _, err := Load(path); err == nil {
+			t.Error("expected error for mismatched keys/values")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := Load(filepath.Join(dir, "does-not-exist.yml")); err == nil {
+			t.Error("expected error for missing manifest file")
+		}
+	})
+}
+
+func TestGroups(t *testing.T) {
+	m := &Manifest{
+		Updates: []Entry{
+			{Files: []string{"a.yaml"}, Type: "key", Keys: []string{"x"}, Values: []string{"1"}, Group: "shared"},
+			{Files: []string{"b.yaml"}, Type: "key", Keys: []string{"y"}, Values: []string{"2"}, Group: "shared"},
+			{Files: []string{"c.yaml"}, Type: "key", Keys: []string{"z"}, Values: []string{"3"}},
+		},
+	}
+
+	groups := m.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	if groups[0].Name != "shared" || len(groups[0].Entries) != 2 {
+		t.Errorf("shared group = %+v, want 2 entries named shared", groups[0])
+	}
+	if len(groups[1].Entries) != 1 {
+		t.Errorf("ungrouped entry should get its own group, got %+v", groups[1])
+	}
+}
+
+func TestExpandFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "b.yaml", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x: 1\n"), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	e := Entry{Files: []string{filepath.Join(dir, "*.yaml")}}
+	files, err := e.ExpandFiles()
+	if err != nil {
+		t.Fatalf("ExpandFiles: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("got %d files, want 2: %v", len(files), files)
+	}
+}
@@ -54,3 +54,106 @@ func LogGroup(title string) {
 func LogEndGroup() {
 	fmt.Println("::endgroup::")
 }
+
+// Annotation locates a Notice/Warning/Error in source so it surfaces
+// inline in the PR "Files changed" view. Any field left at its zero
+// value is omitted from the emitted command.
+type Annotation struct {
+	File  string
+	Line  int
+	Col   int
+	Title string
+}
+
+// Mask registers value with the runner so it's redacted from logs from
+// this point on. Call it on secrets as soon as they're known.
+func Mask(value string) {
+	fmt.Printf("::add-mask::%s\n", value)
+}
+
+// Debug prints a debug message, visible only when step debug logging is
+// enabled.
+func Debug(msg string) {
+	fmt.Printf("::debug::%s\n", escapeData(msg))
+}
+
+// Notice prints an informational annotation, optionally located by ann.
+func Notice(msg string, ann Annotation) {
+	annotate("notice", msg, ann)
+}
+
+// Warning prints a warning annotation located by ann. Unlike LogWarning,
+// this surfaces file/line/col/title so the message is anchored to a
+// specific line in the PR diff.
+func Warning(msg string, ann Annotation) {
+	annotate("warning", msg, ann)
+}
+
+// Error prints an error annotation located by ann. Unlike LogError, this
+// surfaces file/line/col/title so the message is anchored to a specific
+// line in the PR diff.
+func Error(msg string, ann Annotation) {
+	annotate("error", msg, ann)
+}
+
+func annotate(cmd, msg string, ann Annotation) {
+	var params []string
+	if ann.File != "" {
+		params = append(params, "file="+escapeProperty(ann.File))
+	}
+	if ann.Line > 0 {
+		params = append(params, fmt.Sprintf("line=%d", ann.Line))
+	}
+	if ann.Col > 0 {
+		params = append(params, fmt.Sprintf("col=%d", ann.Col))
+	}
+	if ann.Title != "" {
+		params = append(params, "title="+escapeProperty(ann.Title))
+	}
+
+	if len(params) == 0 {
+		fmt.Printf("::%s::%s\n", cmd, escapeData(msg))
+		return
+	}
+	fmt.Printf("::%s %s::%s\n", cmd, strings.Join(params, ","), escapeData(msg))
+}
+
+// escapeData escapes a workflow command's message per the runner's
+// encoding rules.
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	s = strings.ReplaceAll(s, "\n", "%0A")
+	return s
+}
+
+// escapeProperty escapes a workflow command's parameter value, which
+// additionally can't contain a bare ':' or ',' without being misread as
+// the next parameter.
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	s = strings.ReplaceAll(s, ",", "%2C")
+	return s
+}
+
+// AppendSummary appends md to the Job Summary for this step. Like
+// SetOutput, a GITHUB_STEP_SUMMARY file is just appended to directly;
+// when the env var isn't set (e.g. running outside Actions), it falls
+// back to printing the Markdown so it isn't silently dropped.
+func AppendSummary(md string) {
+	summaryFile := os.Getenv("GITHUB_STEP_SUMMARY")
+	if summaryFile == "" {
+		fmt.Println(md)
+		return
+	}
+
+	f, err := os.OpenFile(summaryFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		fmt.Println(md)
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "%s\n", md)
+}
@@ -0,0 +1,267 @@
+package updater
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImageSpec describes one kustomize-style image rewrite: find an image
+// reference by Name and optionally rename it, retag it, or pin it to a
+// digest. NewTag and Digest are mutually exclusive — setting a digest on
+// a previously tagged image drops the tag field (and vice versa), since
+// an image reference can't carry both at once in most of these shapes.
+type ImageSpec struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"new-name,omitempty"`
+	NewTag  string `yaml:"new-tag,omitempty"`
+	Digest  string `yaml:"digest,omitempty"`
+}
+
+// Validate reports an error if the spec is missing its match name or
+// sets both NewTag and Digest.
+func (s ImageSpec) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("image spec is missing 'name'")
+	}
+	if s.NewTag != "" && s.Digest != "" {
+		return fmt.Errorf("image %q: 'new-tag' and 'digest' are mutually exclusive", s.Name)
+	}
+	return nil
+}
+
+// UpdateImages rewrites every image reference in doc that matches one of
+// specs, recognizing Helm-style `repository`+`tag`/`digest` pairs,
+// kustomize `images:` list entries (`name`/`newName`/`newTag`/`digest`),
+// single-string `image: repo:tag@digest` fields (however deeply nested,
+// e.g. under `containers[*].image`/`initContainers[*].image`), rebuilding
+// the string in place so its original quote style is preserved.
+func UpdateImages(doc *Document, specs []ImageSpec) ([]Change, error) {
+	for _, s := range specs {
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	content := doc.Root
+	if doc.Root.Kind == yaml.DocumentNode && len(doc.Root.Content) > 0 {
+		content = doc.Root.Content[0]
+	}
+
+	var changes []Change
+	for _, spec := range specs {
+		walkImages(content, spec, &changes, "", false)
+	}
+
+	return changes, nil
+}
+
+func walkImages(node *yaml.Node, spec ImageSpec, changes *[]Change, path string, inImagesList bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		keyMap := make(map[string]*yaml.Node, len(node.Content)/2)
+		for i := 0; i < len(node.Content); i += 2 {
+			keyMap[node.Content[i].Value] = node.Content[i+1]
+		}
+
+		if repoNode, ok := keyMap["repository"]; ok && imageMatches(repoNode.Value, spec.Name) {
+			applyHelmStyle(node, keyMap, spec, changes, path)
+		}
+
+		if inImagesList {
+			if nameNode, ok := keyMap["name"]; ok && imageMatches(nameNode.Value, spec.Name) {
+				applyKustomizeEntry(node, keyMap, spec, changes, path)
+			}
+		}
+
+		if imgNode, ok := keyMap["image"]; ok && imgNode.Kind == yaml.ScalarNode {
+			applySingleString(imgNode, spec, changes, joinPath(path, "image"))
+		}
+
+		for i := 0; i < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			walkImages(node.Content[i+1], spec, changes, joinPath(path, key), key == "images")
+		}
+
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			childPath := strconv.Itoa(i)
+			if path != "" {
+				childPath = path + "." + childPath
+			}
+			walkImages(child, spec, changes, childPath, inImagesList)
+		}
+	}
+}
+
+// applyHelmStyle rewrites a Helm-style `repository:`/`tag:`/`digest:`
+// mapping, e.g.:
+//
+//	image:
+//	  repository: myorg/webapp
+//	  tag: v1.0.0
+func applyHelmStyle(node *yaml.Node, keyMap map[string]*yaml.Node, spec ImageSpec, changes *[]Change, path string) {
+	if spec.NewName != "" {
+		repoNode := keyMap["repository"]
+		if repoNode.Value != spec.NewName {
+			*changes = append(*changes, Change{Key: joinPath(path, "repository"), Old: repoNode.Value, New: spec.NewName})
+			repoNode.Value = spec.NewName
+		}
+	}
+
+	switch {
+	case spec.Digest != "":
+		old := fieldValue(keyMap, "tag", "digest")
+		if old != spec.Digest {
+			deleteMapKey(node, "tag")
+			setMapScalar(node, "digest", spec.Digest)
+			*changes = append(*changes, Change{Key: joinPath(path, "digest"), Old: old, New: spec.Digest})
+		}
+	case spec.NewTag != "":
+		old := fieldValue(keyMap, "tag")
+		if old != spec.NewTag {
+			deleteMapKey(node, "digest")
+			setMapScalar(node, "tag", spec.NewTag)
+			*changes = append(*changes, Change{Key: joinPath(path, "tag"), Old: old, New: spec.NewTag})
+		}
+	}
+}
+
+// applyKustomizeEntry rewrites one entry of a kustomize `images:` list:
+//
+//	images:
+//	  - name: myorg/webapp
+//	    newTag: v1.0.0
+func applyKustomizeEntry(node *yaml.Node, keyMap map[string]*yaml.Node, spec ImageSpec, changes *[]Change, path string) {
+	if spec.NewName != "" {
+		old := fieldValue(keyMap, "newName")
+		if old != spec.NewName {
+			setMapScalar(node, "newName", spec.NewName)
+			*changes = append(*changes, Change{Key: joinPath(path, "newName"), Old: old, New: spec.NewName})
+		}
+	}
+
+	switch {
+	case spec.Digest != "":
+		old := fieldValue(keyMap, "newTag", "digest")
+		if old != spec.Digest {
+			deleteMapKey(node, "newTag")
+			setMapScalar(node, "digest", spec.Digest)
+			*changes = append(*changes, Change{Key: joinPath(path, "digest"), Old: old, New: spec.Digest})
+		}
+	case spec.NewTag != "":
+		old := fieldValue(keyMap, "newTag")
+		if old != spec.NewTag {
+			deleteMapKey(node, "digest")
+			setMapScalar(node, "newTag", spec.NewTag)
+			*changes = append(*changes, Change{Key: joinPath(path, "newTag"), Old: old, New: spec.NewTag})
+		}
+	}
+}
+
+// applySingleString rewrites a single-string image reference such as
+// `image: myorg/webapp:v1.0.0` or `myorg/webapp@sha256:...`, preserving
+// its quote style since only node.Value changes, not node.Style.
+func applySingleString(node *yaml.Node, spec ImageSpec, changes *[]Change, path string) {
+	repo, tag, digest := parseImageRef(node.Value)
+	if !imageMatches(repo, spec.Name) {
+		return
+	}
+
+	newRepo := repo
+	if spec.NewName != "" {
+		newRepo = spec.NewName
+	}
+
+	newTag, newDigest := tag, digest
+	switch {
+	case spec.Digest != "":
+		newTag, newDigest = "", spec.Digest
+	case spec.NewTag != "":
+		newTag, newDigest = spec.NewTag, ""
+	}
+
+	newRef := buildImageRef(newRepo, newTag, newDigest)
+	if newRef == node.Value {
+		return
+	}
+
+	*changes = append(*changes, Change{Key: path, Old: node.Value, New: newRef})
+	node.Value = newRef
+}
+
+// parseImageRef splits an image reference into repository, tag, and
+// digest. The tag is only recognized after the last path separator, so
+// a registry host with a port (e.g. "registry:5000/app:v1") isn't
+// mistaken for a tag.
+func parseImageRef(ref string) (repo, tag, digest string) {
+	if at := strings.Index(ref, "@"); at != -1 {
+		digest = ref[at+1:]
+		ref = ref[:at]
+	}
+
+	lastSlash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon > lastSlash {
+		return ref[:colon], ref[colon+1:], digest
+	}
+	return ref, "", digest
+}
+
+func buildImageRef(repo, tag, digest string) string {
+	ref := repo
+	if tag != "" {
+		ref += ":" + tag
+	}
+	if digest != "" {
+		ref += "@" + digest
+	}
+	return ref
+}
+
+func imageMatches(repoVal, imageName string) bool {
+	return repoVal == imageName || strings.HasSuffix(repoVal, "/"+imageName)
+}
+
+// fieldValue returns the first populated value among keys present in
+// keyMap, used to report an "old" value that could live in either of
+// two mutually exclusive fields (e.g. tag vs. digest).
+func fieldValue(keyMap map[string]*yaml.Node, keys ...string) string {
+	for _, k := range keys {
+		if n, ok := keyMap[k]; ok {
+			return n.Value
+		}
+	}
+	return ""
+}
+
+func setMapScalar(node *yaml.Node, key, value string) {
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1].Value = value
+			node.Content[i+1].Tag = "!!str"
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+func deleteMapKey(node *yaml.Node, key string) {
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
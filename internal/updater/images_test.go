@@ -0,0 +1,137 @@
+package updater
+
+import "testing"
+
+func TestUpdateImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		specs   []ImageSpec
+		want    int
+		wantErr bool
+	}{
+		{
+			name:  "helm style retag",
+			yaml:  "image:\n  repository: ghcr.io/myorg/webapp\n  tag: v1.0.0\n",
+			specs: []ImageSpec{{Name: "webapp", NewTag: "v2.0.0"}},
+			want:  1,
+		},
+		{
+			name:  "helm style switches tag to digest",
+			yaml:  "image:\n  repository: ghcr.io/myorg/webapp\n  tag: v1.0.0\n",
+			specs: []ImageSpec{{Name: "webapp", Digest: "sha256:abc123"}},
+			want:  1,
+		},
+		{
+			name:  "kustomize list entry gains newName and newTag",
+			yaml:  "images:\n  - name: ghcr.io/myorg/webapp\n",
+			specs: []ImageSpec{{Name: "webapp", NewName: "ghcr.io/myorg/app", NewTag: "v2.0.0"}},
+			want:  2,
+		},
+		{
+			name:  "single string image field",
+			yaml:  "spec:\n  image: ghcr.io/myorg/webapp:v1.0.0\n",
+			specs: []ImageSpec{{Name: "webapp", NewTag: "v2.0.0"}},
+			want:  1,
+		},
+		{
+			name: "containers list nested image fields",
+			yaml: "spec:\n  containers:\n    - name: app\n      image: ghcr.io/myorg/webapp:v1.0.0\n" +
+				"  initContainers:\n    - name: migrate\n      image: ghcr.io/myorg/webapp:v1.0.0\n",
+			specs: []ImageSpec{{Name: "webapp", NewTag: "v2.0.0"}},
+			want:  2,
+		},
+		{
+			name:  "no match leaves document untouched",
+			yaml:  "image:\n  repository: ghcr.io/myorg/other\n  tag: v1.0.0\n",
+			specs: []ImageSpec{{Name: "webapp", NewTag: "v2.0.0"}},
+			want:  0,
+		},
+		{
+			name:    "mutually exclusive new-tag and digest rejected",
+			yaml:    "image:\n  repository: ghcr.io/myorg/webapp\n  tag: v1.0.0\n",
+			specs:   []ImageSpec{{Name: "webapp", NewTag: "v2.0.0", Digest: "sha256:abc123"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := loadDoc(t, tt.yaml)
+
+			changes, err := UpdateImages(doc, tt.specs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateImages error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(changes) != tt.want {
+				t.Errorf("UpdateImages got %d changes, want %d", len(changes), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		name       string
+		ref        string
+		wantRepo   string
+		wantTag    string
+		wantDigest string
+	}{
+		{
+			name:     "repo and tag",
+			ref:      "ghcr.io/myorg/webapp:v1.0.0",
+			wantRepo: "ghcr.io/myorg/webapp",
+			wantTag:  "v1.0.0",
+		},
+		{
+			name:       "repo, tag, and digest",
+			ref:        "ghcr.io/myorg/webapp:v1.0.0@sha256:abc123",
+			wantRepo:   "ghcr.io/myorg/webapp",
+			wantTag:    "v1.0.0",
+			wantDigest: "sha256:abc123",
+		},
+		{
+			name:       "repo and digest, no tag",
+			ref:        "ghcr.io/myorg/webapp@sha256:abc123",
+			wantRepo:   "ghcr.io/myorg/webapp",
+			wantDigest: "sha256:abc123",
+		},
+		{
+			name:     "registry port is not mistaken for a tag",
+			ref:      "registry.internal:5000/myorg/webapp:v1.0.0",
+			wantRepo: "registry.internal:5000/myorg/webapp",
+			wantTag:  "v1.0.0",
+		},
+		{
+			name:     "bare repo with no tag or digest",
+			ref:      "ghcr.io/myorg/webapp",
+			wantRepo: "ghcr.io/myorg/webapp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, tag, digest := parseImageRef(tt.ref)
+			if repo != tt.wantRepo || tag != tt.wantTag || digest != tt.wantDigest {
+				t.Errorf("parseImageRef(%q) = (%q, %q, %q), want (%q, %q, %q)",
+					tt.ref, repo, tag, digest, tt.wantRepo, tt.wantTag, tt.wantDigest)
+			}
+		})
+	}
+}
+
+func TestImageSpecValidate(t *testing.T) {
+	if err := (ImageSpec{}).Validate(); err == nil {
+		t.Error("expected error for missing name")
+	}
+	if err := (ImageSpec{Name: "webapp", NewTag: "v2", Digest: "sha256:abc"}).Validate(); err == nil {
+		t.Error("expected error for mutually exclusive new-tag and digest")
+	}
+	if err := (ImageSpec{Name: "webapp", NewTag: "v2"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package updater
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resolveKeyPath locates the node addressed by keyPath against node,
+// detecting the addressing syntax from keyPath's first character (see
+// UpdateKeys), and returns it together with the path re-expressed in
+// dotted form with any index resolved to a concrete position. That
+// dotted form is what Change.Key reports and what yamlpatch.EnsurePath
+// expects when grafting a key into an overlay.
+func resolveKeyPath(node *yaml.Node, keyPath string) (*yaml.Node, string, error) {
+	switch {
+	case strings.HasPrefix(keyPath, "/"):
+		return resolveJSONPointer(node, keyPath)
+	case strings.HasPrefix(keyPath, "$"):
+		return resolveJSONPath(node, keyPath)
+	default:
+		return walkPathParts(node, strings.Split(keyPath, "."), keyPath)
+	}
+}
+
+// resolveJSONPointer resolves an RFC 6901 JSON Pointer such as
+// "/spec/template/spec/containers/0/image" against node.
+func resolveJSONPointer(node *yaml.Node, pointer string) (*yaml.Node, string, error) {
+	raw := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	parts := make([]string, len(raw))
+	for i, p := range raw {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~1", "/"), "~0", "~")
+	}
+	return walkPathParts(node, parts, pointer)
+}
+
+// walkPathParts walks node along parts — each either a mapping key or,
+// against a sequence, a base-10 index — creating no new nodes (this is
+// the read-side resolver; yamlpatch.EnsurePath handles grafting missing
+// ancestors when writing into an overlay). original is only used for
+// error messages.
+func walkPathParts(node *yaml.Node, parts []string, original string) (*yaml.Node, string, error) {
+	current := node
+
+	for i, part := range parts {
+		switch current.Kind {
+		case yaml.MappingNode:
+			next, ok := mapChild(current, part)
+			if !ok {
+				return nil, "", fmt.Errorf("key '%s' not found in path '%s'", part, original)
+			}
+			current = next
+		case yaml.SequenceNode:
+			idx, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, "", fmt.Errorf("expected integer index for list, got '%s' in path '%s'", part, original)
+			}
+			if idx < 0 || idx >= len(current.Content) {
+				return nil, "", fmt.Errorf("index %d out of range in path '%s'", idx, original)
+			}
+			current = current.Content[idx]
+		default:
+			if i < len(parts)-1 {
+				return nil, "", fmt.Errorf("cannot traverse into scalar at '%s' in path '%s'", part, original)
+			}
+		}
+	}
+
+	return current, strings.Join(parts, "."), nil
+}
+
+// jsonPathToken is one segment of a tokenized JSONPath expression.
+type jsonPathToken struct {
+	key         string // jsonPathKey
+	index       int    // jsonPathIndex
+	filterField string // jsonPathFilter
+	filterValue string // jsonPathFilter
+	kind        jsonPathTokenKind
+}
+
+type jsonPathTokenKind int
+
+const (
+	jsonPathKey jsonPathTokenKind = iota
+	jsonPathIndex
+	jsonPathFilter
+)
+
+// resolveJSONPath resolves a subset of JSONPath against node: dotted
+// member access ("$.a.b"), bracketed list indices ("$.a[2]"), and a
+// single equality filter predicate per segment to pick a list element
+// by field instead of position ("$.a[?(@.name==\"api\")]"). Anything
+// beyond that subset (wildcards, slices, multiple predicates, script
+// expressions, ...) is rejected with an error rather than silently
+// misinterpreted.
+func resolveJSONPath(node *yaml.Node, path string) (*yaml.Node, string, error) {
+	tokens, err := tokenizeJSONPath(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	current := node
+	var resolved []string
+
+	for _, tok := range tokens {
+		switch tok.kind {
+		case jsonPathKey:
+			next, ok := mapChild(current, tok.key)
+			if !ok {
+				return nil, "", fmt.Errorf("key '%s' not found in jsonpath '%s'", tok.key, path)
+			}
+			current = next
+			resolved = append(resolved, tok.key)
+
+		case jsonPathIndex:
+			if current.Kind != yaml.SequenceNode {
+				return nil, "", fmt.Errorf("index %d requires a list in jsonpath '%s'", tok.index, path)
+			}
+			if tok.index < 0 || tok.index >= len(current.Content) {
+				return nil, "", fmt.Errorf("index %d out of range in jsonpath '%s'", tok.index, path)
+			}
+			current = current.Content[tok.index]
+			resolved = append(resolved, strconv.Itoa(tok.index))
+
+		case jsonPathFilter:
+			if current.Kind != yaml.SequenceNode {
+				return nil, "", fmt.Errorf("filter predicate requires a list in jsonpath '%s'", path)
+			}
+			idx, ok := findFilterMatch(current, tok.filterField, tok.filterValue)
+			if !ok {
+				return nil, "", fmt.Errorf("no element matches @.%s==\"%s\" in jsonpath '%s'", tok.filterField, tok.filterValue, path)
+			}
+			current = current.Content[idx]
+			resolved = append(resolved, strconv.Itoa(idx))
+		}
+	}
+
+	return current, strings.Join(resolved, "."), nil
+}
+
+// tokenizeJSONPath splits a JSONPath expression (its leading "$"
+// stripped) into key/index/filter tokens.
+func tokenizeJSONPath(path string) ([]jsonPathToken, error) {
+	s := strings.TrimPrefix(path, "$")
+
+	var tokens []jsonPathToken
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			if i+1 < len(s) && s[i+1] == '.' {
+				return nil, fmt.Errorf("recursive descent '..' is not supported in jsonpath '%s'", path)
+			}
+			i++
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated '[' in jsonpath '%s'", path)
+			}
+			inner := s[i+1 : i+end]
+			i += end + 1
+
+			if strings.HasPrefix(inner, "?(") && strings.HasSuffix(inner, ")") {
+				field, value, err := parseFilterPredicate(strings.TrimSuffix(strings.TrimPrefix(inner, "?("), ")"))
+				if err != nil {
+					return nil, fmt.Errorf("%w in jsonpath '%s'", err, path)
+				}
+				tokens = append(tokens, jsonPathToken{kind: jsonPathFilter, filterField: field, filterValue: value})
+				continue
+			}
+
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index '%s' in jsonpath '%s'", inner, path)
+			}
+			tokens = append(tokens, jsonPathToken{kind: jsonPathIndex, index: idx})
+		default:
+			end := i
+			for end < len(s) && s[end] != '.' && s[end] != '[' {
+				end++
+			}
+			tokens = append(tokens, jsonPathToken{kind: jsonPathKey, key: s[i:end]})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// parseFilterPredicate parses the inside of a "?(...)" filter, which
+// this package only supports in the form @.field=="value" (or with
+// single quotes).
+func parseFilterPredicate(pred string) (field, value string, err error) {
+	eq := strings.Index(pred, "==")
+	if eq == -1 {
+		return "", "", fmt.Errorf("unsupported filter predicate %q (only @.field==\"value\" is supported)", pred)
+	}
+
+	lhs := strings.TrimSpace(pred[:eq])
+	if !strings.HasPrefix(lhs, "@.") {
+		return "", "", fmt.Errorf("unsupported filter predicate %q (left side must be @.field)", pred)
+	}
+
+	rhs := strings.Trim(strings.TrimSpace(pred[eq+2:]), `"'`)
+	return strings.TrimPrefix(lhs, "@."), rhs, nil
+}
+
+// findFilterMatch returns the index of the first mapping in seq whose
+// field equals value.
+func findFilterMatch(seq *yaml.Node, field, value string) (int, bool) {
+	for i, item := range seq.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if v, ok := mapChild(item, field); ok && v.Value == value {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// mapChild returns the value node for key in a mapping node.
+func mapChild(node *yaml.Node, key string) (*yaml.Node, bool) {
+	if node.Kind != yaml.MappingNode {
+		return nil, false
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1], true
+		}
+	}
+	return nil, false
+}
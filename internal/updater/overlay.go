@@ -0,0 +1,185 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dnd-it/action-yaml-update/internal/yamlpatch"
+	"gopkg.in/yaml.v3"
+)
+
+// Overlaid bundles a base document stream with an optional ".local"
+// override loaded on top of it, so update operations can read the
+// effective (merged) value while choosing whether the result gets
+// written back to the base file or to the overlay. The overlay only
+// ever targets the first document of the stream — overlay files are
+// themselves meant to be a single small patch — so Base[1:] (additional
+// documents in a multi-document base file) pass straight through to
+// Merged untouched and can never be redirected to the overlay.
+type Overlaid struct {
+	BasePath    string
+	Base        []*Document
+	OverlayPath string
+	Overlay     *Document   // nil if no overlay file exists yet; applies to Base[0] only
+	Merged      []*Document // Base with [0] deep-merged with Overlay; aliases Base entirely when there's no overlay
+
+	suffix string // the overlay suffix LoadYAMLWithOverlays was called with, used by ensureOverlay
+}
+
+// LoadYAMLWithOverlays loads basePath and deep-merges any overlay
+// file(s) found via yamlpatch.OverlayPaths(basePath, suffix, env) onto
+// its first document, most specific last. When no overlay file exists,
+// Merged aliases Base, so callers can use Overlaid uniformly whether or
+// not an override is actually present.
+func LoadYAMLWithOverlays(basePath, suffix, env string) (*Overlaid, error) {
+	baseContent, err := os.ReadFile(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s: %w", basePath, err)
+	}
+
+	base, err := LoadYAML(baseContent)
+	if err != nil {
+		return nil, fmt.Errorf("parse yaml %s: %w", basePath, err)
+	}
+
+	if suffix == "" {
+		suffix = ".local"
+	}
+	ov := &Overlaid{BasePath: basePath, Base: base, Merged: base, suffix: suffix}
+
+	for _, path := range yamlpatch.OverlayPaths(basePath, suffix, env) {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read overlay %s: %w", path, err)
+		}
+
+		overlayDocs, err := LoadYAML(content)
+		if err != nil {
+			return nil, fmt.Errorf("parse overlay %s: %w", path, err)
+		}
+		if len(overlayDocs) == 0 || len(ov.Merged) == 0 {
+			continue
+		}
+
+		ov.OverlayPath = path
+		ov.Overlay = overlayDocs[0]
+
+		merged := append([]*Document(nil), ov.Merged...)
+		merged[0] = &Document{
+			Root:   yamlpatch.Merge(ov.Merged[0].Root, ov.Overlay.Root),
+			Indent: base[0].Indent,
+		}
+		ov.Merged = merged
+	}
+
+	return ov, nil
+}
+
+// ApplyUpdates runs fn (typically UpdateKeys, UpdateImageTags or
+// UpdateImages) once per document in the merged view to discover what
+// should change, then persists each document's changes by key path into
+// the corresponding base document, or — for the first document only,
+// when writeToOverlay is set — into the overlay document, creating it
+// and grafting any missing ancestor keys as needed. Every Change's
+// DocIndex is set to the index of the document it came from. Documents
+// left untouched (base when writing to the overlay, or vice versa) are
+// left exactly as loaded.
+func (ov *Overlaid) ApplyUpdates(writeToOverlay bool, fn func(doc *Document) ([]Change, error)) ([]Change, error) {
+	var allChanges []Change
+
+	for i, merged := range ov.Merged {
+		target := ov.Base[i]
+		if writeToOverlay && i == 0 {
+			target = ov.ensureOverlay()
+		}
+
+		// fn mutates the Document it's given in place. When merged
+		// already aliases target (no overlay in play for this
+		// document) that's exactly what we want. Otherwise the
+		// change is destined for a different document (the overlay
+		// when writeToOverlay is set, or the base document when an
+		// overlay was loaded but writeToOverlay is not), so fn must
+		// run against a scratch copy to avoid corrupting the
+		// document that's meant to be left untouched.
+		scratch := merged
+		if target != merged {
+			scratch = &Document{Root: cloneNode(merged.Root), Indent: merged.Indent}
+		}
+
+		changes, err := fn(scratch)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		for c := range changes {
+			changes[c].DocIndex = i
+		}
+
+		if target == merged {
+			allChanges = append(allChanges, changes...)
+			continue
+		}
+
+		targetContent := documentContent(target)
+		mergedContent := documentContent(scratch)
+
+		for _, c := range changes {
+			leaf, err := yamlpatch.EnsurePath(targetContent, c.Key, mergedContent)
+			if err != nil {
+				return nil, fmt.Errorf("write %s: %w", c.Key, err)
+			}
+			coerced := coerceValue(fmt.Sprintf("%v", c.New), leaf)
+			leaf.Value = coerced
+		}
+
+		allChanges = append(allChanges, changes...)
+	}
+
+	return allChanges, nil
+}
+
+// ensureOverlay returns ov.Overlay, creating an empty overlay document
+// (and defaulting OverlayPath to the first overlay candidate) if one
+// hasn't been loaded yet.
+func (ov *Overlaid) ensureOverlay() *Document {
+	if ov.Overlay != nil {
+		return ov.Overlay
+	}
+
+	if ov.OverlayPath == "" {
+		ov.OverlayPath = ov.BasePath + ov.suffix
+	}
+	ov.Overlay = &Document{
+		Root:   &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"},
+		Indent: ov.Base[0].Indent,
+	}
+	return ov.Overlay
+}
+
+func documentContent(doc *Document) *yaml.Node {
+	if doc.Root.Kind == yaml.DocumentNode && len(doc.Root.Content) > 0 {
+		return doc.Root.Content[0]
+	}
+	return doc.Root
+}
+
+// cloneNode deep-copies a yaml.Node tree so it can be handed to fn
+// without risking a mutation of the original.
+func cloneNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	clone := *n
+	if n.Content != nil {
+		clone.Content = make([]*yaml.Node, len(n.Content))
+		for i, c := range n.Content {
+			clone.Content[i] = cloneNode(c)
+		}
+	}
+	return &clone
+}
@@ -0,0 +1,130 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadYAMLWithOverlays(t *testing.T) {
+	t.Run("no overlay file: Merged aliases Base", func(t *testing.T) {
+		dir := t.TempDir()
+		base := filepath.Join(dir, "values.yaml")
+		if err := os.WriteFile(base, []byte("app:\n  version: v1\n"), 0644); err != nil {
+			t.Fatalf("write base: %v", err)
+		}
+
+		ov, err := LoadYAMLWithOverlays(base, "", "")
+		if err != nil {
+			t.Fatalf("LoadYAMLWithOverlays: %v", err)
+		}
+		if ov.Overlay != nil {
+			t.Error("expected no overlay to be loaded")
+		}
+		if len(ov.Merged) != len(ov.Base) || &ov.Merged[0] != &ov.Base[0] {
+			t.Error("expected Merged to alias Base when no overlay exists")
+		}
+	})
+
+	t.Run("overlay file overrides the base value", func(t *testing.T) {
+		dir := t.TempDir()
+		base := filepath.Join(dir, "values.yaml")
+		if err := os.WriteFile(base, []byte("app:\n  version: v1\n  name: web\n"), 0644); err != nil {
+			t.Fatalf("write base: %v", err)
+		}
+		if err := os.WriteFile(base+".local", []byte("app:\n  version: v2\n"), 0644); err != nil {
+			t.Fatalf("write overlay: %v", err)
+		}
+
+		ov, err := LoadYAMLWithOverlays(base, "", "")
+		if err != nil {
+			t.Fatalf("LoadYAMLWithOverlays: %v", err)
+		}
+		if ov.Overlay == nil {
+			t.Fatal("expected overlay to be loaded")
+		}
+
+		changes, err := UpdateKeys(ov.Merged[0], []string{"app.version"}, []string{"v2"})
+		if err != nil {
+			t.Fatalf("UpdateKeys: %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("got %d changes, want 0 (overlay already holds v2)", len(changes))
+		}
+	})
+}
+
+func TestOverlaidApplyUpdates(t *testing.T) {
+	t.Run("writes to base when WriteToOverlay is false", func(t *testing.T) {
+		dir := t.TempDir()
+		base := filepath.Join(dir, "values.yaml")
+		if err := os.WriteFile(base, []byte("app:\n  version: v1\n"), 0644); err != nil {
+			t.Fatalf("write base: %v", err)
+		}
+
+		ov, err := LoadYAMLWithOverlays(base, "", "")
+		if err != nil {
+			t.Fatalf("LoadYAMLWithOverlays: %v", err)
+		}
+
+		changes, err := ov.ApplyUpdates(false, func(doc *Document) ([]Change, error) {
+			return UpdateKeys(doc, []string{"app.version"}, []string{"v2"})
+		})
+		if err != nil {
+			t.Fatalf("ApplyUpdates: %v", err)
+		}
+		if len(changes) != 1 {
+			t.Fatalf("got %d changes, want 1", len(changes))
+		}
+
+		out, err := DumpYAML(ov.Base)
+		if err != nil {
+			t.Fatalf("DumpYAML: %v", err)
+		}
+		if want := "app:\n  version: v2\n"; string(out) != want {
+			t.Errorf("base = %q, want %q", out, want)
+		}
+	})
+
+	t.Run("grafts the change into a fresh overlay when WriteToOverlay is true", func(t *testing.T) {
+		dir := t.TempDir()
+		base := filepath.Join(dir, "values.yaml")
+		if err := os.WriteFile(base, []byte("app:\n  version: v1\n  name: web\n"), 0644); err != nil {
+			t.Fatalf("write base: %v", err)
+		}
+
+		ov, err := LoadYAMLWithOverlays(base, "", "")
+		if err != nil {
+			t.Fatalf("LoadYAMLWithOverlays: %v", err)
+		}
+
+		changes, err := ov.ApplyUpdates(true, func(doc *Document) ([]Change, error) {
+			return UpdateKeys(doc, []string{"app.version"}, []string{"v2"})
+		})
+		if err != nil {
+			t.Fatalf("ApplyUpdates: %v", err)
+		}
+		if len(changes) != 1 {
+			t.Fatalf("got %d changes, want 1", len(changes))
+		}
+
+		baseOut, err := DumpYAML(ov.Base)
+		if err != nil {
+			t.Fatalf("DumpYAML base: %v", err)
+		}
+		if want := "app:\n  version: v1\n  name: web\n"; string(baseOut) != want {
+			t.Errorf("base should be untouched: got %q, want %q", baseOut, want)
+		}
+
+		overlayOut, err := DumpYAML([]*Document{ov.Overlay})
+		if err != nil {
+			t.Fatalf("DumpYAML overlay: %v", err)
+		}
+		if want := "app:\n  version: v2\n"; string(overlayOut) != want {
+			t.Errorf("overlay = %q, want %q", overlayOut, want)
+		}
+		if ov.OverlayPath != base+".local" {
+			t.Errorf("OverlayPath = %q, want %q", ov.OverlayPath, base+".local")
+		}
+	})
+}
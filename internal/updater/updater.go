@@ -9,45 +9,132 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Change represents a single value change made to the YAML.
+// Change represents a single value change made to the YAML. File and
+// DocIndex are left blank/zero by UpdateKeys/UpdateImageTags/
+// UpdateImages themselves, since they operate on an already-loaded
+// Document with no notion of where it came from or its position in a
+// multi-document stream; callers that loop over multiple files and
+// documents (see cmd/yaml-update) fill them in afterwards for
+// reporting. Key is always reported in dotted form, resolved to
+// concrete indices, regardless of which addressing syntax (dotted,
+// JSON Pointer, JSONPath) located it.
 type Change struct {
-	Key string
-	Old any
-	New any
+	File     string
+	DocIndex int
+	Key      string
+	Old      any
+	New      any
 }
 
-// Document wraps a yaml.Node with detected indentation.
+// Document is one YAML document within a stream, wrapping a yaml.Node
+// with its detected indentation. LeadingBlank is the number of blank
+// lines that separated it from the previous document's "---" marker in
+// the original source; it's meaningless for a stream's first document,
+// which has no separator before it.
 type Document struct {
-	Root   *yaml.Node
-	Indent int
+	Root         *yaml.Node
+	Indent       int
+	LeadingBlank int
 }
 
-// LoadYAML parses YAML content into a Document for format-preserving editing.
-func LoadYAML(content []byte) (*Document, error) {
-	var node yaml.Node
-	if err := yaml.Unmarshal(content, &node); err != nil {
-		return nil, fmt.Errorf("parse yaml: %w", err)
+// LoadYAML parses content into an ordered stream of Documents for
+// format-preserving editing. Content is usually a single YAML document,
+// but concatenated Kubernetes manifests and `helm template` output
+// commonly stack several "---"-separated documents in one file; each
+// becomes its own Document so callers can address them by index.
+func LoadYAML(content []byte) ([]*Document, error) {
+	indent := detectIndent(content)
+
+	var docs []*Document
+	for _, segment := range splitDocuments(content) {
+		text, leadingBlank := trimLeadingBlankLines(segment)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(text), &node); err != nil {
+			return nil, fmt.Errorf("parse yaml document %d: %w", len(docs), err)
+		}
+
+		docs = append(docs, &Document{Root: &node, Indent: indent, LeadingBlank: leadingBlank})
 	}
 
-	indent := detectIndent(content)
+	return docs, nil
+}
+
+// DumpYAML serializes a stream of Documents back to bytes, preserving
+// each one's formatting and writing a "---" separator (and the blank
+// lines that followed it) between consecutive documents. A "---" before
+// the first document, a comment on a separator line, and empty
+// documents dropped by LoadYAML are not reproduced.
+func DumpYAML(docs []*Document) ([]byte, error) {
+	var out strings.Builder
+
+	for i, doc := range docs {
+		if i > 0 {
+			out.WriteString("---\n")
+			out.WriteString(strings.Repeat("\n", doc.LeadingBlank))
+		}
+
+		var buf strings.Builder
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(doc.Indent)
+
+		if err := enc.Encode(doc.Root); err != nil {
+			return nil, err
+		}
+
+		out.WriteString(buf.String())
+	}
+
+	return []byte(out.String()), nil
+}
 
-	return &Document{
-		Root:   &node,
-		Indent: indent,
-	}, nil
+// splitDocuments splits raw YAML content on "---" document-separator
+// lines (a line containing only "---", optionally followed by a
+// comment). Like kubectl and helm's own manifest splitting, it matches
+// by line shape rather than tracking YAML structure, so a literal
+// "---" line inside a block scalar would be misread as a separator;
+// that's rare enough in practice to accept.
+func splitDocuments(content []byte) [][]byte {
+	lines := strings.Split(string(content), "\n")
+
+	var segments [][]byte
+	start := 0
+	for i, line := range lines {
+		if isDocSeparator(line) {
+			segments = append(segments, []byte(strings.Join(lines[start:i], "\n")))
+			start = i + 1
+		}
+	}
+	segments = append(segments, []byte(strings.Join(lines[start:], "\n")))
+
+	return segments
 }
 
-// DumpYAML serializes a Document back to bytes, preserving formatting.
-func DumpYAML(doc *Document) ([]byte, error) {
-	var buf strings.Builder
-	enc := yaml.NewEncoder(&buf)
-	enc.SetIndent(doc.Indent)
+func isDocSeparator(line string) bool {
+	trimmed := strings.TrimRight(line, "\r")
+	if trimmed == "---" {
+		return true
+	}
+	rest := strings.TrimPrefix(trimmed, "---")
+	return rest != trimmed && strings.HasPrefix(strings.TrimSpace(rest), "#")
+}
 
-	if err := enc.Encode(doc.Root); err != nil {
-		return nil, err
+// trimLeadingBlankLines strips blank lines from the start of segment,
+// returning the remaining text and how many lines were removed, so
+// LoadYAML can record them as the following Document's LeadingBlank and
+// DumpYAML can put them back before its "---".
+func trimLeadingBlankLines(segment []byte) (string, int) {
+	lines := strings.Split(string(segment), "\n")
+
+	n := 0
+	for n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+		n++
 	}
 
-	return []byte(buf.String()), nil
+	return strings.Join(lines[n:], "\n"), n
 }
 
 func detectIndent(content []byte) int {
@@ -65,7 +152,17 @@ func detectIndent(content []byte) int {
 	return 2
 }
 
-// UpdateKeys updates values at dot-notation key paths.
+// UpdateKeys updates values at the given key paths, each of which may
+// use any of three addressing syntaxes, detected from its first
+// character: a leading "/" is an RFC 6901 JSON Pointer
+// ("/spec/template/spec/containers/0/image"), a leading "$" is a subset
+// of JSONPath that additionally supports a single equality filter
+// predicate per segment to select a list element by field instead of
+// position ("$.spec.template.spec.containers[?(@.name==\"api\")].image"),
+// and anything else is this package's original dot-separated path
+// ("spec.template.spec.containers.0.image"). Whichever syntax locates
+// it, the resulting Change.Key always reports the dotted form with any
+// filter predicate resolved to the concrete index it matched.
 func UpdateKeys(doc *Document, keys, values []string) ([]Change, error) {
 	var changes []Change
 
@@ -78,7 +175,7 @@ func UpdateKeys(doc *Document, keys, values []string) ([]Change, error) {
 	for i, keyPath := range keys {
 		newValue := values[i]
 
-		node, err := resolveKeyPath(content, keyPath)
+		node, resolvedPath, err := resolveKeyPath(content, keyPath)
 		if err != nil {
 			return nil, err
 		}
@@ -88,7 +185,7 @@ func UpdateKeys(doc *Document, keys, values []string) ([]Change, error) {
 
 		if node.Value != coerced {
 			changes = append(changes, Change{
-				Key: keyPath,
+				Key: resolvedPath,
 				Old: oldValue,
 				New: parseValue(coerced),
 			})
@@ -125,40 +222,6 @@ func UpdateImageTags(doc *Document, imageName, newTag string) []Change {
 	return changes
 }
 
-func resolveKeyPath(node *yaml.Node, keyPath string) (*yaml.Node, error) {
-	parts := strings.Split(keyPath, ".")
-	current := node
-
-	for i, part := range parts {
-		if current.Kind == yaml.MappingNode {
-			found := false
-			for j := 0; j < len(current.Content); j += 2 {
-				if current.Content[j].Value == part {
-					current = current.Content[j+1]
-					found = true
-					break
-				}
-			}
-			if !found {
-				return nil, fmt.Errorf("key '%s' not found in path '%s'", part, keyPath)
-			}
-		} else if current.Kind == yaml.SequenceNode {
-			idx, err := strconv.Atoi(part)
-			if err != nil {
-				return nil, fmt.Errorf("expected integer index for list, got '%s' in path '%s'", part, keyPath)
-			}
-			if idx < 0 || idx >= len(current.Content) {
-				return nil, fmt.Errorf("index %d out of range in path '%s'", idx, keyPath)
-			}
-			current = current.Content[idx]
-		} else if i < len(parts)-1 {
-			return nil, fmt.Errorf("cannot traverse into scalar at '%s' in path '%s'", part, keyPath)
-		}
-	}
-
-	return current, nil
-}
-
 func walkImageTags(node *yaml.Node, imageName, newTag string, changes *[]Change, path string) {
 	switch node.Kind {
 	case yaml.MappingNode:
@@ -7,6 +7,21 @@ import (
 	"testing"
 )
 
+// loadDoc loads content as a single-document stream and returns its
+// only Document, failing the test if parsing produced an error or
+// anything other than exactly one document.
+func loadDoc(t *testing.T, content string) *Document {
+	t.Helper()
+	docs, err := LoadYAML([]byte(content))
+	if err != nil {
+		t.Fatalf("LoadYAML error: %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("LoadYAML got %d documents, want 1", len(docs))
+	}
+	return docs[0]
+}
+
 func TestUpdateKeys(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -69,10 +84,7 @@ func TestUpdateKeys(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			doc, err := LoadYAML([]byte(tt.yaml))
-			if err != nil {
-				t.Fatalf("LoadYAML error: %v", err)
-			}
+			doc := loadDoc(t, tt.yaml)
 
 			changes, err := UpdateKeys(doc, tt.keys, tt.values)
 			if (err != nil) != tt.wantErr {
@@ -86,6 +98,54 @@ func TestUpdateKeys(t *testing.T) {
 	}
 }
 
+func TestUpdateKeysAddressing(t *testing.T) {
+	yaml := "spec:\n  template:\n    spec:\n      containers:\n        - name: sidecar\n          image: v1\n        - name: api\n          image: v1\n"
+
+	tests := []struct {
+		name    string
+		key     string
+		wantKey string
+	}{
+		{
+			name:    "dotted path",
+			key:     "spec.template.spec.containers.1.image",
+			wantKey: "spec.template.spec.containers.1.image",
+		},
+		{
+			name:    "json pointer",
+			key:     "/spec/template/spec/containers/1/image",
+			wantKey: "spec.template.spec.containers.1.image",
+		},
+		{
+			name:    "jsonpath with index",
+			key:     "$.spec.template.spec.containers[1].image",
+			wantKey: "spec.template.spec.containers.1.image",
+		},
+		{
+			name:    "jsonpath with filter predicate",
+			key:     `$.spec.template.spec.containers[?(@.name=="api")].image`,
+			wantKey: "spec.template.spec.containers.1.image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := loadDoc(t, yaml)
+
+			changes, err := UpdateKeys(doc, []string{tt.key}, []string{"v2"})
+			if err != nil {
+				t.Fatalf("UpdateKeys error: %v", err)
+			}
+			if len(changes) != 1 {
+				t.Fatalf("got %d changes, want 1", len(changes))
+			}
+			if changes[0].Key != tt.wantKey {
+				t.Errorf("Key = %q, want %q", changes[0].Key, tt.wantKey)
+			}
+		})
+	}
+}
+
 func TestTypeCoercion(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -119,7 +179,7 @@ func TestTypeCoercion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			doc, _ := LoadYAML([]byte(tt.yaml))
+			doc := loadDoc(t, tt.yaml)
 			changes, _ := UpdateKeys(doc, []string{tt.key}, []string{tt.value})
 
 			if len(changes) == 0 {
@@ -191,7 +251,7 @@ func TestUpdateImageTags(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			doc, _ := LoadYAML([]byte(tt.yaml))
+			doc := loadDoc(t, tt.yaml)
 			changes := UpdateImageTags(doc, tt.imageName, tt.newTag)
 
 			if len(changes) != tt.want {
@@ -209,9 +269,9 @@ app:
   version: v1.0.0  # inline
   name: test
 `
-		doc, _ := LoadYAML([]byte(yaml))
+		doc := loadDoc(t, yaml)
 		UpdateKeys(doc, []string{"app.version"}, []string{"v2.0.0"})
-		result, _ := DumpYAML(doc)
+		result, _ := DumpYAML([]*Document{doc})
 
 		if !strings.Contains(string(result), "# Top comment") {
 			t.Error("top comment not preserved")
@@ -229,9 +289,9 @@ app:
   image:
     tag: v1.0.0
 `
-		doc, _ := LoadYAML([]byte(yaml))
+		doc := loadDoc(t, yaml)
 		UpdateKeys(doc, []string{"app.image.tag"}, []string{"v2.0.0"})
-		result, _ := DumpYAML(doc)
+		result, _ := DumpYAML([]*Document{doc})
 
 		if !strings.Contains(string(result), "  ports:") {
 			t.Errorf("2-space indentation not preserved, got:\n%s", result)
@@ -246,9 +306,9 @@ app:
     image:
         tag: v1.0.0
 `
-		doc, _ := LoadYAML([]byte(yaml))
+		doc := loadDoc(t, yaml)
 		UpdateKeys(doc, []string{"app.image.tag"}, []string{"v2.0.0"})
-		result, _ := DumpYAML(doc)
+		result, _ := DumpYAML([]*Document{doc})
 
 		if !strings.Contains(string(result), "    ports:") {
 			t.Errorf("4-space indentation not preserved, got:\n%s", result)
@@ -256,6 +316,48 @@ app:
 	})
 }
 
+func TestLoadYAMLMultiDocument(t *testing.T) {
+	t.Run("splits on document separators", func(t *testing.T) {
+		content := "a: 1\n---\nb: 2\n---\nc: 3\n"
+		docs, err := LoadYAML([]byte(content))
+		if err != nil {
+			t.Fatalf("LoadYAML error: %v", err)
+		}
+		if len(docs) != 3 {
+			t.Fatalf("got %d documents, want 3", len(docs))
+		}
+	})
+
+	t.Run("round-trips separators and blank lines", func(t *testing.T) {
+		content := "a: 1\n---\n\nb: 2\n"
+		docs, err := LoadYAML([]byte(content))
+		if err != nil {
+			t.Fatalf("LoadYAML error: %v", err)
+		}
+		if len(docs) != 2 {
+			t.Fatalf("got %d documents, want 2", len(docs))
+		}
+
+		out, err := DumpYAML(docs)
+		if err != nil {
+			t.Fatalf("DumpYAML error: %v", err)
+		}
+		if string(out) != content {
+			t.Errorf("DumpYAML = %q, want %q", out, content)
+		}
+	})
+
+	t.Run("empty documents are skipped", func(t *testing.T) {
+		docs, err := LoadYAML([]byte("---\n---\n"))
+		if err != nil {
+			t.Fatalf("LoadYAML error: %v", err)
+		}
+		if len(docs) != 0 {
+			t.Errorf("got %d documents, want 0", len(docs))
+		}
+	})
+}
+
 func TestDiff(t *testing.T) {
 	t.Run("shows changes", func(t *testing.T) {
 		original := "app:\n  version: v1.0.0\n"
@@ -297,7 +399,7 @@ initContainers:
       repository: ghcr.io/myorg/api
       tag: v1.0.0
 `
-	doc, _ := LoadYAML([]byte(yaml))
+	doc := loadDoc(t, yaml)
 	changes := UpdateImageTags(doc, "api", "v5.0.0")
 
 	if len(changes) != 2 {
@@ -317,12 +419,15 @@ func TestLoadFromFile(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	doc, err := LoadYAML(data)
+	docs, err := LoadYAML(data)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(docs) != 1 {
+		t.Fatalf("LoadYAML got %d documents, want 1", len(docs))
+	}
 
-	changes, err := UpdateKeys(doc, []string{"app.version"}, []string{"v2.0.0"})
+	changes, err := UpdateKeys(docs[0], []string{"app.version"}, []string{"v2.0.0"})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,223 @@
+// Package yamlpatch implements crowdsec-style ".local" overlay merging
+// for YAML documents: values declared in a sibling override file are
+// deep-merged on top of a base document, with the override winning.
+package yamlpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OverrideTag, when set on a sequence node in the overlay document,
+// makes Merge replace the base list wholesale instead of appending to
+// it, e.g.:
+//
+//	env: !override
+//	  - staging
+const OverrideTag = "!override"
+
+// OverlayPaths returns the sibling overlay file(s) that should be merged
+// on top of basePath, in increasing order of precedence: the generic
+// overlay first, then the environment-specific one (if env is set) so
+// it wins over both the base and the generic overlay. suffix defaults
+// to ".local" when empty.
+func OverlayPaths(basePath, suffix, env string) []string {
+	if suffix == "" {
+		suffix = ".local"
+	}
+
+	paths := []string{basePath + suffix}
+	if env != "" {
+		paths = append(paths, basePath+"."+env+suffix)
+	}
+	return paths
+}
+
+// Merge deep-merges overlay onto base and returns a new node tree:
+// mapping keys present in overlay take precedence, recursing into
+// nested mappings. Sequences are appended (base entries followed by
+// overlay entries) unless the overlay node carries OverrideTag, in
+// which case the overlay sequence replaces the base one entirely.
+// Scalars and mismatched node kinds are decided in overlay's favor.
+// Neither input is mutated.
+func Merge(base, overlay *yaml.Node) *yaml.Node {
+	if overlay == nil {
+		return cloneNode(base)
+	}
+	if base == nil {
+		return cloneNode(overlay)
+	}
+
+	if base.Kind == yaml.DocumentNode || overlay.Kind == yaml.DocumentNode {
+		merged := Merge(documentContent(base), documentContent(overlay))
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{merged}}
+	}
+
+	if base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode {
+		return mergeMappings(base, overlay)
+	}
+
+	if base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode {
+		return mergeSequences(base, overlay)
+	}
+
+	return cloneNode(overlay)
+}
+
+// documentContent unwraps a top-level DocumentNode to its single content
+// node, mirroring how updater.Document.Root is handled throughout.
+func documentContent(n *yaml.Node) *yaml.Node {
+	if n != nil && n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		return n.Content[0]
+	}
+	return n
+}
+
+func mergeMappings(base, overlay *yaml.Node) *yaml.Node {
+	merged := cloneNode(base)
+	merged.Content = nil
+
+	overlayIdx := make(map[string]int, len(overlay.Content)/2)
+	for i := 0; i < len(overlay.Content); i += 2 {
+		overlayIdx[overlay.Content[i].Value] = i
+	}
+
+	seen := make(map[string]bool, len(base.Content)/2)
+	for i := 0; i < len(base.Content); i += 2 {
+		key, baseVal := base.Content[i], base.Content[i+1]
+		seen[key.Value] = true
+
+		if j, ok := overlayIdx[key.Value]; ok {
+			merged.Content = append(merged.Content, cloneNode(key), Merge(baseVal, overlay.Content[j+1]))
+		} else {
+			merged.Content = append(merged.Content, cloneNode(key), cloneNode(baseVal))
+		}
+	}
+
+	for i := 0; i < len(overlay.Content); i += 2 {
+		key, overlayVal := overlay.Content[i], overlay.Content[i+1]
+		if seen[key.Value] {
+			continue
+		}
+		merged.Content = append(merged.Content, cloneNode(key), cloneNode(overlayVal))
+	}
+
+	return merged
+}
+
+func mergeSequences(base, overlay *yaml.Node) *yaml.Node {
+	if overlay.Tag == OverrideTag {
+		replaced := cloneNode(overlay)
+		replaced.Tag = ""
+		return replaced
+	}
+
+	merged := cloneNode(base)
+	merged.Content = make([]*yaml.Node, 0, len(base.Content)+len(overlay.Content))
+	for _, item := range base.Content {
+		merged.Content = append(merged.Content, cloneNode(item))
+	}
+	for _, item := range overlay.Content {
+		merged.Content = append(merged.Content, cloneNode(item))
+	}
+	return merged
+}
+
+func cloneNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	clone := *n
+	if n.Content != nil {
+		clone.Content = make([]*yaml.Node, len(n.Content))
+		for i, c := range n.Content {
+			clone.Content[i] = cloneNode(c)
+		}
+	}
+	return &clone
+}
+
+// EnsurePath walks root (a mapping node) along the dot-separated
+// keyPath, creating empty mapping nodes for any missing ancestors, and
+// returns the leaf node so the caller can set its value. template is
+// consulted for the leaf's tag/style when it has to be created (e.g.
+// the merged view that already holds the value being written), so a
+// freshly grafted overlay key keeps the same scalar type as the value
+// it's overriding. root must not be nil. Only mapping ancestors are
+// synthesized; a keyPath segment that needs to index into a sequence
+// that doesn't already exist on root is an error, same as
+// updater.UpdateKeys' read-side path resolution.
+func EnsurePath(root *yaml.Node, keyPath string, template *yaml.Node) (*yaml.Node, error) {
+	current := root
+	templateCurrent := template
+	parts := strings.Split(keyPath, ".")
+
+	for i, part := range parts {
+		if current.Kind == yaml.SequenceNode {
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(current.Content) {
+				return nil, fmt.Errorf("cannot graft %q: %q is not a valid index into %q", keyPath, part, strings.Join(parts[:i], "."))
+			}
+			current = current.Content[idx]
+			if templateCurrent != nil && templateCurrent.Kind == yaml.SequenceNode && idx < len(templateCurrent.Content) {
+				templateCurrent = templateCurrent.Content[idx]
+			} else {
+				templateCurrent = nil
+			}
+			continue
+		}
+		if current.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("cannot graft %q: %q is not a mapping", keyPath, strings.Join(parts[:i], "."))
+		}
+
+		var next *yaml.Node
+		for j := 0; j < len(current.Content); j += 2 {
+			if current.Content[j].Value == part {
+				next = current.Content[j+1]
+				break
+			}
+		}
+
+		var templateNext *yaml.Node
+		if templateCurrent != nil && templateCurrent.Kind == yaml.MappingNode {
+			for j := 0; j < len(templateCurrent.Content); j += 2 {
+				if templateCurrent.Content[j].Value == part {
+					templateNext = templateCurrent.Content[j+1]
+					break
+				}
+			}
+		}
+
+		if next == nil {
+			if templateNext != nil && templateNext.Kind == yaml.SequenceNode {
+				return nil, fmt.Errorf("cannot graft %q: creating new sequence entries is not supported", keyPath)
+			}
+
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}
+			if i == len(parts)-1 {
+				next = leafNode(templateNext)
+			} else {
+				next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			current.Content = append(current.Content, keyNode, next)
+		}
+
+		current = next
+		templateCurrent = templateNext
+	}
+
+	return current, nil
+}
+
+// leafNode builds a new scalar node to hold a value being grafted into
+// an overlay, copying tag/style from template (the corresponding node
+// in the merged or base tree) so e.g. integer tags round-trip.
+func leafNode(template *yaml.Node) *yaml.Node {
+	if template != nil && template.Kind == yaml.ScalarNode {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: template.Tag, Style: template.Style}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+}
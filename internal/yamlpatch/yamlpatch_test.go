@@ -0,0 +1,149 @@
+package yamlpatch
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func parse(t *testing.T, content string) *yaml.Node {
+	t.Helper()
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &node); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return &node
+}
+
+func dump(t *testing.T, node *yaml.Node) string {
+	t.Helper()
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(out)
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		overlay string
+		want    string
+	}{
+		{
+			name:    "overlay scalar wins",
+			base:    "app:\n  version: v1\n",
+			overlay: "app:\n  version: v2\n",
+			want:    "app:\n    version: v2\n",
+		},
+		{
+			name:    "overlay adds new key without dropping base keys",
+			base:    "app:\n  name: web\n",
+			overlay: "app:\n  replicas: 3\n",
+			want:    "app:\n    name: web\n    replicas: 3\n",
+		},
+		{
+			name:    "sequences append by default",
+			base:    "env:\n  - a\n  - b\n",
+			overlay: "env:\n  - c\n",
+			want:    "env:\n    - a\n    - b\n    - c\n",
+		},
+		{
+			name:    "override tag replaces the sequence",
+			base:    "env:\n  - a\n  - b\n",
+			overlay: "env: !override\n  - c\n",
+			want:    "env:\n    - c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := parse(t, tt.base)
+			overlay := parse(t, tt.overlay)
+			merged := Merge(base, overlay)
+
+			if got := dump(t, merged); got != tt.want {
+				t.Errorf("Merge() = %q, want %q", got, tt.want)
+			}
+			// base is untouched: compare against a freshly parsed copy of
+			// tt.base rather than the literal, since dump's indent width
+			// doesn't match the 2-space fixture strings.
+			if got, want := dump(t, base), dump(t, parse(t, tt.base)); got != want {
+				t.Errorf("Merge mutated base: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestOverlayPaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		suffix string
+		env    string
+		want   []string
+	}{
+		{"default suffix, no env", "", "", []string{"values.yaml.local"}},
+		{"custom suffix, no env", ".override", "", []string{"values.yaml.override"}},
+		{"default suffix with env", "", "prod", []string{"values.yaml.local", "values.yaml.prod.local"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OverlayPaths("values.yaml", tt.suffix, tt.env)
+			if len(got) != len(tt.want) {
+				t.Fatalf("OverlayPaths() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("OverlayPaths()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEnsurePath(t *testing.T) {
+	t.Run("grafts missing ancestors and matches template tag", func(t *testing.T) {
+		root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		template := parse(t, "app:\n  replicas: 3\n")
+
+		leaf, err := EnsurePath(root, "app.replicas", template)
+		if err != nil {
+			t.Fatalf("EnsurePath: %v", err)
+		}
+		leaf.Value = "5"
+
+		want := "app:\n    replicas: 5\n"
+		if got := dump(t, root); got != want {
+			t.Errorf("EnsurePath() tree = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("errors when the path needs a new sequence entry", func(t *testing.T) {
+		root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		template := parse(t, "containers:\n  - image: web:v1\n")
+
+		if _, err := EnsurePath(root, "containers.0.image", template); err == nil {
+			t.Error("expected error when grafting into a not-yet-existing sequence")
+		}
+	})
+
+	t.Run("reuses an existing node instead of duplicating it", func(t *testing.T) {
+		root := parse(t, "app:\n  replicas: 3\n")
+
+		leaf, err := EnsurePath(root, "app.replicas", root)
+		if err != nil {
+			t.Fatalf("EnsurePath: %v", err)
+		}
+		leaf.Value = "7"
+
+		want := "app:\n    replicas: 7\n"
+		if got := dump(t, root); got != want {
+			t.Errorf("EnsurePath() tree = %q, want %q", got, want)
+		}
+	})
+}